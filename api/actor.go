@@ -0,0 +1,27 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentActor builds the business actor from the authenticated request
+func currentActor(c *gin.Context) business.Actor {
+	return business.Actor{
+		UserID: middleware.GetUserID(c),
+		Role:   middleware.GetUserRole(c),
+	}
+}
+
+// errorStatus maps a service error to an HTTP status, using fallback for
+// errors without a more specific status
+func errorStatus(err error, fallback int) int {
+	if errors.Is(err, business.ErrForbidden) {
+		return http.StatusForbidden
+	}
+	return fallback
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler struct {
+	addressService *business.AddressService
+}
+
+func NewAddressHandler(addressService *business.AddressService) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+// @Summary Create saved address
+// @Description Save a delivery address for the authenticated user
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param address body models.UserAddress true "Address object"
+// @Success 201 {object} models.UserAddress
+// @Failure 400 {object} map[string]string
+// @Router /me/addresses [post]
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var address models.UserAddress
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.addressService.CreateAddress(middleware.GetUserID(c), &address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, address)
+}
+
+// @Summary Get saved addresses
+// @Description Get the saved delivery addresses of the authenticated user, default first
+// @Tags addresses
+// @Produce json
+// @Success 200 {array} models.UserAddress
+// @Failure 500 {object} map[string]string
+// @Router /me/addresses [get]
+func (h *AddressHandler) GetAddresses(c *gin.Context) {
+	addresses, err := h.addressService.GetAddresses(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, addresses)
+}
+
+// @Summary Update saved address
+// @Description Update a saved delivery address of the authenticated user
+// @Tags addresses
+// @Accept json
+// @Produce json
+// @Param address_id path int true "Address ID"
+// @Param address body models.UserAddress true "Address object"
+// @Success 200 {object} models.UserAddress
+// @Failure 400 {object} map[string]string
+// @Router /me/addresses/{address_id} [put]
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	addressID, err := strconv.ParseUint(c.Param("address_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address ID"})
+		return
+	}
+
+	var address models.UserAddress
+	if err := c.ShouldBindJSON(&address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	address.ID = uint(addressID)
+
+	if err := h.addressService.UpdateAddress(middleware.GetUserID(c), &address); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, address)
+}
+
+// @Summary Delete saved address
+// @Description Delete a saved delivery address of the authenticated user
+// @Tags addresses
+// @Produce json
+// @Param address_id path int true "Address ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /me/addresses/{address_id} [delete]
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	addressID, err := strconv.ParseUint(c.Param("address_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address ID"})
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(middleware.GetUserID(c), uint(addressID)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "address deleted successfully"})
+}
+
+// RegisterRoutes registers the routes for the address handler
+func (h *AddressHandler) RegisterRoutes(router *gin.RouterGroup) {
+	addresses := router.Group("/me/addresses")
+	{
+		addresses.POST("", h.CreateAddress)
+		addresses.GET("", h.GetAddresses)
+		addresses.PUT("/:address_id", h.UpdateAddress)
+		addresses.DELETE("/:address_id", h.DeleteAddress)
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CartHandler struct {
+	cartService *business.CartService
+}
+
+func NewCartHandler(cartService *business.CartService) *CartHandler {
+	return &CartHandler{cartService: cartService}
+}
+
+// @Summary Get cart
+// @Description Get the authenticated user's cart with current prices
+// @Tags cart
+// @Produce json
+// @Success 200 {object} models.Cart
+// @Failure 500 {object} map[string]string
+// @Router /cart [get]
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cart, err := h.cartService.GetCart(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Add cart item
+// @Description Add a dish to the cart. A cart can only hold dishes of one restaurant.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param item body models.CartItemRequest true "Dish and quantity"
+// @Success 200 {object} models.Cart
+// @Failure 400 {object} map[string]string
+// @Router /cart/items [post]
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var request models.CartItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.cartService.AddItem(middleware.GetUserID(c), &request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Update cart item quantity
+// @Description Change the quantity of a cart item, removing it when the quantity is 0
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param item_id path int true "Cart item ID"
+// @Param item body models.CartItemUpdate true "New quantity"
+// @Success 200 {object} models.Cart
+// @Failure 400 {object} map[string]string
+// @Router /cart/items/{item_id} [put]
+func (h *CartHandler) UpdateItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cart item ID"})
+		return
+	}
+
+	var update models.CartItemUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.cartService.UpdateItemQuantity(middleware.GetUserID(c), uint(itemID), update.Quantity)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Remove cart item
+// @Description Remove an item from the cart
+// @Tags cart
+// @Produce json
+// @Param item_id path int true "Cart item ID"
+// @Success 200 {object} models.Cart
+// @Failure 400 {object} map[string]string
+// @Router /cart/items/{item_id} [delete]
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cart item ID"})
+		return
+	}
+
+	cart, err := h.cartService.RemoveItem(middleware.GetUserID(c), uint(itemID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Clear cart
+// @Description Remove every item from the cart
+// @Tags cart
+// @Produce json
+// @Success 200 {object} models.Cart
+// @Failure 500 {object} map[string]string
+// @Router /cart [delete]
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	cart, err := h.cartService.ClearCart(middleware.GetUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, cart)
+}
+
+// @Summary Checkout cart
+// @Description Place an order for the items in the cart and empty it
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param checkout body models.CartCheckout false "Delivery details"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} map[string]string
+// @Router /cart/checkout [post]
+func (h *CartHandler) Checkout(c *gin.Context) {
+	var checkout models.CartCheckout
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&checkout); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	order, err := h.cartService.Checkout(middleware.GetUserID(c), &checkout)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// RegisterRoutes registers the routes for the cart handler
+func (h *CartHandler) RegisterRoutes(router *gin.RouterGroup) {
+	cart := router.Group("/cart")
+	{
+		cart.GET("", h.GetCart)
+		cart.DELETE("", h.ClearCart)
+		cart.POST("/items", h.AddItem)
+		cart.PUT("/items/:item_id", h.UpdateItem)
+		cart.DELETE("/items/:item_id", h.RemoveItem)
+		cart.POST("/checkout", h.Checkout)
+	}
+}
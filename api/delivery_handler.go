@@ -0,0 +1,232 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeliveryHandler struct {
+	deliveryService *business.DeliveryService
+}
+
+func NewDeliveryHandler(deliveryService *business.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{deliveryService: deliveryService}
+}
+
+// @Summary Get driver profile
+// @Description Get the driver profile of the authenticated driver
+// @Tags drivers
+// @Produce json
+// @Success 200 {object} models.Driver
+// @Failure 404 {object} map[string]string
+// @Router /drivers/me [get]
+func (h *DeliveryHandler) GetProfile(c *gin.Context) {
+	driver, err := h.deliveryService.GetProfile(currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, driver)
+}
+
+// @Summary Update driver profile
+// @Description Create or update the vehicle details of the authenticated driver
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param driver body models.Driver true "Vehicle details"
+// @Success 200 {object} models.Driver
+// @Failure 400 {object} map[string]string
+// @Router /drivers/me [put]
+func (h *DeliveryHandler) UpdateProfile(c *gin.Context) {
+	var driver models.Driver
+	if err := c.ShouldBindJSON(&driver); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	actor := currentActor(c)
+	if err := h.deliveryService.UpdateProfile(&driver, actor); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.deliveryService.GetProfile(actor)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Set driver availability
+// @Description Start or end the authenticated driver's shift. Only available drivers are offered orders.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param availability body models.DriverAvailability true "Availability"
+// @Success 200 {object} models.Driver
+// @Failure 400 {object} map[string]string
+// @Router /drivers/me/availability [put]
+func (h *DeliveryHandler) SetAvailability(c *gin.Context) {
+	var availability models.DriverAvailability
+	if err := c.ShouldBindJSON(&availability); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	driver, err := h.deliveryService.SetAvailability(*availability.IsAvailable, currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, driver)
+}
+
+// @Summary Send driver location
+// @Description Record the authenticated driver's current location, adding it to the route of the orders they are delivering
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param location body models.DriverLocationUpdate true "Coordinates"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /drivers/me/location [post]
+func (h *DeliveryHandler) UpdateLocation(c *gin.Context) {
+	var update models.DriverLocationUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.deliveryService.UpdateLocation(&update, currentActor(c)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Location updated successfully"})
+}
+
+// @Summary Get delivery offers
+// @Description Get the delivery offers awaiting the authenticated driver's answer
+// @Tags drivers
+// @Produce json
+// @Success 200 {array} models.DeliveryOffer
+// @Failure 500 {object} map[string]string
+// @Router /drivers/me/offers [get]
+func (h *DeliveryHandler) GetOffers(c *gin.Context) {
+	offers, err := h.deliveryService.GetOffers(currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, offers)
+}
+
+// @Summary Accept delivery offer
+// @Description Accept a delivery offer, assigning its order to the authenticated driver
+// @Tags drivers
+// @Produce json
+// @Param offer_id path int true "Offer ID"
+// @Success 200 {object} models.DeliveryOffer
+// @Failure 400 {object} map[string]string
+// @Router /drivers/me/offers/{offer_id}/accept [post]
+func (h *DeliveryHandler) AcceptOffer(c *gin.Context) {
+	offerID, err := strconv.ParseUint(c.Param("offer_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return
+	}
+
+	offer, err := h.deliveryService.AcceptOffer(uint(offerID), currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, offer)
+}
+
+// @Summary Decline delivery offer
+// @Description Decline a delivery offer, so its order is offered to another driver
+// @Tags drivers
+// @Produce json
+// @Param offer_id path int true "Offer ID"
+// @Success 200 {object} models.DeliveryOffer
+// @Failure 400 {object} map[string]string
+// @Router /drivers/me/offers/{offer_id}/decline [post]
+func (h *DeliveryHandler) DeclineOffer(c *gin.Context) {
+	offerID, err := strconv.ParseUint(c.Param("offer_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return
+	}
+
+	offer, err := h.deliveryService.DeclineOffer(uint(offerID), currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, offer)
+}
+
+// @Summary Get active deliveries
+// @Description Get the orders the authenticated driver is delivering
+// @Tags drivers
+// @Produce json
+// @Success 200 {array} models.Order
+// @Failure 500 {object} map[string]string
+// @Router /drivers/me/deliveries [get]
+func (h *DeliveryHandler) GetDeliveries(c *gin.Context) {
+	orders, err := h.deliveryService.GetDeliveries(currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// @Summary Track order delivery
+// @Description Get the driver of an order and the route they have taken so far
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.DeliveryTracking
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/tracking [get]
+func (h *DeliveryHandler) GetTracking(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	tracking, err := h.deliveryService.GetTracking(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tracking)
+}
+
+// RegisterRoutes registers the routes for the delivery handler
+func (h *DeliveryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	drivers := router.Group("/drivers/me")
+	drivers.Use(middleware.RequireRoles(models.UserRoleDriver))
+	{
+		drivers.GET("", h.GetProfile)
+		drivers.PUT("", h.UpdateProfile)
+		drivers.PUT("/availability", h.SetAvailability)
+		drivers.POST("/location", h.UpdateLocation)
+		drivers.GET("/offers", h.GetOffers)
+		drivers.POST("/offers/:offer_id/accept", h.AcceptOffer)
+		drivers.POST("/offers/:offer_id/decline", h.DeclineOffer)
+		drivers.GET("/deliveries", h.GetDeliveries)
+	}
+
+	router.GET("/orders/:id/tracking", h.GetTracking)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -34,11 +35,16 @@ func NewDishHandler(dishService *business.DishService, imageHandler *ImageHandle
 // @Param restaurant_id path int true "Restaurant ID"
 // @Param name formData string true "Dish name"
 // @Param description formData string true "Dish description"
-// @Param price formData number true "Dish price"
+// @Param price formData string true "Dish price in major units, e.g. 16.99"
+// @Param currency formData string false "ISO 4217 currency code, defaults to the restaurant's currency"
 // @Param category formData string true "Dish category"
+// @Param menu_category_id formData int false "Menu category ID"
+// @Param display_order formData int false "Position within the menu category"
+// @Param prep_time_minutes formData int false "Minutes the kitchen takes to prepare the dish, 0 for the default"
 // @Param image formData file false "Dish image"
 // @Success 201 {object} models.Dish
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
 // @Router /restaurants/{restaurant_id}/dishes [post]
 func (h *DishHandler) CreateDish(c *gin.Context) {
 	restaurantID, err := strconv.ParseUint(c.Param("restaurant_id"), 10, 32)
@@ -47,7 +53,17 @@ func (h *DishHandler) CreateDish(c *gin.Context) {
 		return
 	}
 
-	price, err := strconv.ParseFloat(c.PostForm("price"), 64)
+	if err := h.dishService.AuthorizeRestaurant(uint(restaurantID), currentActor(c)); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	currency, err := h.dishService.RestaurantCurrency(uint(restaurantID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	price, err := models.ParseMoney(c.PostForm("price"), c.DefaultPostForm("currency", currency))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid price"})
 		return
@@ -60,6 +76,14 @@ func (h *DishHandler) CreateDish(c *gin.Context) {
 		Price:        price,
 		Category:     c.PostForm("category"),
 	}
+	if err := parseMenuPlacement(c, &dish); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := parsePrepTime(c, &dish); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Handle image upload
 	if imageFile, err := c.FormFile("image"); err == nil {
@@ -71,8 +95,8 @@ func (h *DishHandler) CreateDish(c *gin.Context) {
 		dish.ImageURL = imageURL
 	}
 
-	if err := h.dishService.CreateDish(&dish); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.dishService.CreateDish(&dish, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -88,8 +112,12 @@ func (h *DishHandler) CreateDish(c *gin.Context) {
 // @Param dish_id path int true "Dish ID"
 // @Param name formData string false "Dish name"
 // @Param description formData string false "Dish description"
-// @Param price formData number false "Dish price"
+// @Param price formData string false "Dish price in major units, e.g. 16.99"
+// @Param currency formData string false "ISO 4217 currency code"
 // @Param category formData string false "Dish category"
+// @Param menu_category_id formData int false "Menu category ID"
+// @Param display_order formData int false "Position within the menu category"
+// @Param prep_time_minutes formData int false "Minutes the kitchen takes to prepare the dish, 0 for the default"
 // @Param image formData file false "Dish image"
 // @Success 200 {object} models.Dish
 // @Failure 400 {object} map[string]string
@@ -120,6 +148,11 @@ func (h *DishHandler) UpdateDish(c *gin.Context) {
 		return
 	}
 
+	if err := h.dishService.AuthorizeRestaurant(dish.RestaurantID, currentActor(c)); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Update fields if provided
 	if name := c.PostForm("name"); name != "" {
 		dish.Name = name
@@ -128,16 +161,24 @@ func (h *DishHandler) UpdateDish(c *gin.Context) {
 		dish.Description = description
 	}
 	if price := c.PostForm("price"); price != "" {
-		priceFloat, err := strconv.ParseFloat(price, 64)
+		parsedPrice, err := models.ParseMoney(price, c.DefaultPostForm("currency", dish.Price.Currency))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid price"})
 			return
 		}
-		dish.Price = priceFloat
+		dish.Price = parsedPrice
 	}
 	if category := c.PostForm("category"); category != "" {
 		dish.Category = category
 	}
+	if err := parseMenuPlacement(c, dish); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := parsePrepTime(c, dish); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Handle image upload
 	if imageFile, err := c.FormFile("image"); err == nil {
@@ -156,14 +197,50 @@ func (h *DishHandler) UpdateDish(c *gin.Context) {
 		dish.ImageURL = imageURL
 	}
 
-	if err := h.dishService.UpdateDish(dish); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.dishService.UpdateDish(dish, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, dish)
 }
 
+// parseMenuPlacement reads the optional menu_category_id and display_order form
+// fields. A menu_category_id of 0 removes the dish from its category.
+func parseMenuPlacement(c *gin.Context, dish *models.Dish) error {
+	if value, ok := c.GetPostForm("menu_category_id"); ok {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return errors.New("invalid menu_category_id")
+		}
+		dish.MenuCategoryID = nil
+		if id != 0 {
+			categoryID := uint(id)
+			dish.MenuCategoryID = &categoryID
+		}
+	}
+	if value, ok := c.GetPostForm("display_order"); ok {
+		order, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("invalid display_order")
+		}
+		dish.DisplayOrder = order
+	}
+	return nil
+}
+
+// parsePrepTime reads the optional prep_time_minutes form field
+func parsePrepTime(c *gin.Context, dish *models.Dish) error {
+	if value, ok := c.GetPostForm("prep_time_minutes"); ok {
+		minutes, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("invalid prep_time_minutes")
+		}
+		dish.PrepTimeMinutes = minutes
+	}
+	return nil
+}
+
 // Helper function to upload image
 func (h *DishHandler) uploadImage(file *multipart.FileHeader, dishID uint) (string, error) {
 	// Create a temporary file
@@ -202,7 +279,13 @@ func (h *DishHandler) uploadImage(file *multipart.FileHeader, dishID uint) (stri
 // @Tags dishes
 // @Produce json
 // @Param restaurant_id path int true "Restaurant ID"
-// @Success 200 {array} models.Dish
+// @Param category query string false "Filter by category"
+// @Param menu_category_id query int false "Filter by menu category"
+// @Param is_available query boolean false "Filter by availability"
+// @Param sort query string false "Sort field (id, name, price, category, display_order, created_at), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Dish]
 // @Failure 404 {object} map[string]string
 // @Router /restaurants/{restaurant_id}/dishes [get]
 func (h *DishHandler) GetDishesByRestaurantID(c *gin.Context) {
@@ -212,9 +295,15 @@ func (h *DishHandler) GetDishesByRestaurantID(c *gin.Context) {
 		return
 	}
 
-	dishes, err := h.dishService.GetDishesByRestaurantID(uint(restaurantID))
+	filter, page, err := parseDishListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "dishes not found"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dishes, err := h.dishService.GetDishesByRestaurantID(uint(restaurantID), filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, dishes)
@@ -248,7 +337,13 @@ func (h *DishHandler) GetDishByID(c *gin.Context) {
 // @Tags dishes
 // @Produce json
 // @Param restaurant_id path int true "Restaurant ID"
-// @Success 200 {array} models.Dish
+// @Param category query string false "Filter by category"
+// @Param menu_category_id query int false "Filter by menu category"
+// @Param is_available query boolean false "Filter by availability"
+// @Param sort query string false "Sort field (id, name, price, category, display_order, created_at), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Dish]
 // @Failure 400 {object} map[string]string
 // @Router /restaurants/{restaurant_id}/dishes [get]
 func (h *DishHandler) GetAllDishes(c *gin.Context) {
@@ -258,7 +353,13 @@ func (h *DishHandler) GetAllDishes(c *gin.Context) {
 		return
 	}
 
-	dishes, err := h.dishService.GetDishesByRestaurantID(uint(restaurantID))
+	filter, page, err := parseDishListQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dishes, err := h.dishService.GetDishesByRestaurantID(uint(restaurantID), filter, page)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -302,6 +403,11 @@ func (h *DishHandler) DeleteDish(c *gin.Context) {
 		return
 	}
 
+	if err := h.dishService.DeleteDish(uint(dishID), currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
 	// Delete dish image if exists
 	if dish.ImageURL != "" {
 		if err := h.imageHandler.DeleteImageByURL(dish.ImageURL); err != nil {
@@ -310,12 +416,186 @@ func (h *DishHandler) DeleteDish(c *gin.Context) {
 		}
 	}
 
-	if err := h.dishService.DeleteDish(uint(dishID)); err != nil {
+	c.JSON(http.StatusOK, gin.H{"message": "Dish deleted successfully"})
+}
+
+// @Summary Get dish modifiers
+// @Description Get the modifier groups of a dish with their options
+// @Tags dishes
+// @Produce json
+// @Param restaurant_id path int true "Restaurant ID"
+// @Param dish_id path int true "Dish ID"
+// @Success 200 {array} models.ModifierGroup
+// @Failure 404 {object} map[string]string
+// @Router /restaurant-dishes/{restaurant_id}/{dish_id}/modifiers [get]
+func (h *DishHandler) GetModifierGroups(c *gin.Context) {
+	dish, ok := h.dishInRestaurant(c)
+	if !ok {
+		return
+	}
+
+	groups, err := h.dishService.GetModifierGroups(dish.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// @Summary Create modifier group
+// @Description Add a modifier group with its options to a dish
+// @Tags dishes
+// @Accept json
+// @Produce json
+// @Param restaurant_id path int true "Restaurant ID"
+// @Param dish_id path int true "Dish ID"
+// @Param group body models.ModifierGroup true "Modifier group with options"
+// @Success 201 {object} models.ModifierGroup
+// @Failure 400 {object} map[string]string
+// @Router /restaurant-dishes/{restaurant_id}/{dish_id}/modifiers [post]
+func (h *DishHandler) CreateModifierGroup(c *gin.Context) {
+	dish, ok := h.dishInRestaurant(c)
+	if !ok {
+		return
+	}
+
+	var group models.ModifierGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Dish deleted successfully"})
+	if err := h.dishService.CreateModifierGroup(dish.ID, &group, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, group)
+}
+
+// @Summary Update modifier group
+// @Description Update a modifier group. Options sent with an ID are updated, options without one are added and missing options are removed.
+// @Tags dishes
+// @Accept json
+// @Produce json
+// @Param restaurant_id path int true "Restaurant ID"
+// @Param dish_id path int true "Dish ID"
+// @Param group_id path int true "Modifier group ID"
+// @Param group body models.ModifierGroup true "Modifier group with options"
+// @Success 200 {object} models.ModifierGroup
+// @Failure 400 {object} map[string]string
+// @Router /restaurant-dishes/{restaurant_id}/{dish_id}/modifiers/{group_id} [put]
+func (h *DishHandler) UpdateModifierGroup(c *gin.Context) {
+	dish, ok := h.dishInRestaurant(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("group_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid modifier group id"})
+		return
+	}
+
+	var group models.ModifierGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	group.ID = uint(groupID)
+
+	if err := h.dishService.UpdateModifierGroup(dish.ID, &group, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, group)
+}
+
+// @Summary Delete modifier group
+// @Description Remove a modifier group and its options from a dish
+// @Tags dishes
+// @Produce json
+// @Param restaurant_id path int true "Restaurant ID"
+// @Param dish_id path int true "Dish ID"
+// @Param group_id path int true "Modifier group ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /restaurant-dishes/{restaurant_id}/{dish_id}/modifiers/{group_id} [delete]
+func (h *DishHandler) DeleteModifierGroup(c *gin.Context) {
+	dish, ok := h.dishInRestaurant(c)
+	if !ok {
+		return
+	}
+
+	groupID, err := strconv.ParseUint(c.Param("group_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid modifier group id"})
+		return
+	}
+
+	if err := h.dishService.DeleteModifierGroup(dish.ID, uint(groupID), currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Modifier group deleted successfully"})
+}
+
+// @Summary Update dish stock
+// @Description Set the daily stock of a dish and the portions left today. Any restaurant member may adjust stock.
+// @Tags dishes
+// @Accept json
+// @Produce json
+// @Param restaurant_id path int true "Restaurant ID"
+// @Param dish_id path int true "Dish ID"
+// @Param stock body models.DishStockUpdate true "Daily and remaining stock, daily_stock null to stop tracking"
+// @Success 200 {object} models.Dish
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /restaurant-dishes/{restaurant_id}/{dish_id}/stock [put]
+func (h *DishHandler) UpdateStock(c *gin.Context) {
+	dish, ok := h.dishInRestaurant(c)
+	if !ok {
+		return
+	}
+
+	var update models.DishStockUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.dishService.UpdateStock(dish.ID, &update, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// dishInRestaurant loads the dish from the path and checks that it belongs to the
+// restaurant in the path. It writes the error response and returns false otherwise.
+func (h *DishHandler) dishInRestaurant(c *gin.Context) (*models.Dish, bool) {
+	restaurantID, err := strconv.ParseUint(c.Param("restaurant_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant id"})
+		return nil, false
+	}
+
+	dishID, err := strconv.ParseUint(c.Param("dish_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dish id"})
+		return nil, false
+	}
+
+	dish, err := h.dishService.GetDishByID(uint(dishID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dish not found"})
+		return nil, false
+	}
+	if dish.RestaurantID != uint(restaurantID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "dish does not belong to the specified restaurant"})
+		return nil, false
+	}
+	return dish, true
 }
 
 // RegisterRoutes registers the routes for the dish handler
@@ -327,5 +607,10 @@ func (h *DishHandler) RegisterRoutes(router *gin.RouterGroup) {
 		dishes.GET("/:restaurant_id/:dish_id", h.GetDishByID)
 		dishes.PUT("/:restaurant_id/:dish_id", h.UpdateDish)
 		dishes.DELETE("/:restaurant_id/:dish_id", h.DeleteDish)
+		dishes.PUT("/:restaurant_id/:dish_id/stock", h.UpdateStock)
+		dishes.GET("/:restaurant_id/:dish_id/modifiers", h.GetModifierGroups)
+		dishes.POST("/:restaurant_id/:dish_id/modifiers", h.CreateModifierGroup)
+		dishes.PUT("/:restaurant_id/:dish_id/modifiers/:group_id", h.UpdateModifierGroup)
+		dishes.DELETE("/:restaurant_id/:dish_id/modifiers/:group_id", h.DeleteModifierGroup)
 	}
 }
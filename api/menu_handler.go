@@ -0,0 +1,171 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MenuHandler struct {
+	menuService *business.MenuService
+}
+
+func NewMenuHandler(menuService *business.MenuService) *MenuHandler {
+	return &MenuHandler{menuService: menuService}
+}
+
+// @Summary Get restaurant menu
+// @Description Get the currently available dishes of a restaurant grouped by menu category in display order
+// @Tags menu
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {object} models.Menu
+// @Failure 404 {object} map[string]string
+// @Router /restaurants/{id}/menu [get]
+func (h *MenuHandler) GetMenu(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	menu, err := h.menuService.GetMenu(uint(restaurantID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, menu)
+}
+
+// @Summary Get menu categories
+// @Description Get all menu categories of a restaurant, including inactive ones
+// @Tags menu
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {array} models.MenuCategory
+// @Failure 404 {object} map[string]string
+// @Router /restaurants/{id}/menu-categories [get]
+func (h *MenuHandler) GetCategories(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	categories, err := h.menuService.GetCategories(uint(restaurantID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// @Summary Create menu category
+// @Description Add a menu category to a restaurant
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param category body models.MenuCategory true "Menu category"
+// @Success 201 {object} models.MenuCategory
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/menu-categories [post]
+func (h *MenuHandler) CreateCategory(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	var category models.MenuCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.menuService.CreateCategory(uint(restaurantID), &category, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// @Summary Update menu category
+// @Description Update the name, display order, availability window or active state of a menu category
+// @Tags menu
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param category_id path int true "Menu category ID"
+// @Param category body models.MenuCategory true "Menu category"
+// @Success 200 {object} models.MenuCategory
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/menu-categories/{category_id} [put]
+func (h *MenuHandler) UpdateCategory(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid menu category ID format"})
+		return
+	}
+
+	var category models.MenuCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	category.ID = uint(categoryID)
+
+	if err := h.menuService.UpdateCategory(uint(restaurantID), &category, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// @Summary Delete menu category
+// @Description Delete a menu category. Its dishes stay on the menu without a category.
+// @Tags menu
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param category_id path int true "Menu category ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/menu-categories/{category_id} [delete]
+func (h *MenuHandler) DeleteCategory(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+	categoryID, err := strconv.ParseUint(c.Param("category_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid menu category ID format"})
+		return
+	}
+
+	if err := h.menuService.DeleteCategory(uint(restaurantID), uint(categoryID), currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Menu category deleted successfully"})
+}
+
+// RegisterRoutes registers the routes for the menu handler
+func (h *MenuHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/restaurants/:id/menu", h.GetMenu)
+	router.GET("/restaurants/:id/menu-categories", h.GetCategories)
+	router.POST("/restaurants/:id/menu-categories", h.CreateCategory)
+	router.PUT("/restaurants/:id/menu-categories/:category_id", h.UpdateCategory)
+	router.DELETE("/restaurants/:id/menu-categories/:category_id", h.DeleteCategory)
+}
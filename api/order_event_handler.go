@@ -0,0 +1,143 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"tumdum_backend/business"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// streamKeepAliveInterval keeps idle streams from being closed by proxies
+const streamKeepAliveInterval = 25 * time.Second
+
+type OrderEventHandler struct {
+	orderService *business.OrderService
+}
+
+func NewOrderEventHandler(orderService *business.OrderService) *OrderEventHandler {
+	return &OrderEventHandler{orderService: orderService}
+}
+
+// @Summary Stream order events
+// @Description Server-Sent Events stream of an order's changes. The first "order" event carries the order as it is; the stream ends once the order is delivered or cancelled.
+// @Tags orders
+// @Produce text/event-stream
+// @Param id path int true "Order ID"
+// @Param token query string false "JWT, for clients that cannot set the Authorization header"
+// @Success 200 {object} models.OrderEvent
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/events [get]
+func (h *OrderEventHandler) StreamOrderEvents(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	order, subscription, err := h.orderService.SubscribeOrder(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("order", order)
+	c.Writer.Flush()
+	if order.Status.IsFinal() {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAliveInterval)
+	defer keepAlive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-subscription.Events():
+			// A closed subscription fell behind; the client reconnects and starts over
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return !event.Status.IsFinal()
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// @Summary Live restaurant orders
+// @Description WebSocket for restaurant dashboards. The first message lists the pending orders; every new order and change to the restaurant's orders follows as an order event.
+// @Tags orders
+// @Param id path int true "Restaurant ID"
+// @Param token query string false "JWT, for clients that cannot set the Authorization header"
+// @Success 101 {object} models.OrderEvent
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/orders/live [get]
+func (h *OrderEventHandler) RestaurantOrdersSocket(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID"})
+		return
+	}
+
+	orders, subscription, err := h.orderService.SubscribeRestaurantOrders(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	// Connections are authenticated by token rather than cookies, so the origin is not checked
+	server := websocket.Server{Handler: func(conn *websocket.Conn) {
+		defer conn.Close()
+
+		// Dashboards only listen; reading tells when they disconnect
+		disconnected := make(chan struct{})
+		go func() {
+			defer close(disconnected)
+			var message string
+			for websocket.Message.Receive(conn, &message) == nil {
+			}
+		}()
+
+		if err := websocket.JSON.Send(conn, gin.H{"type": "orders.pending", "orders": orders}); err != nil {
+			return
+		}
+		keepAlive := time.NewTicker(streamKeepAliveInterval)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case event, ok := <-subscription.Events():
+				if !ok {
+					return
+				}
+				if err := websocket.JSON.Send(conn, event); err != nil {
+					return
+				}
+			case <-keepAlive.C:
+				if err := websocket.JSON.Send(conn, gin.H{"type": "keep-alive"}); err != nil {
+					return
+				}
+			case <-disconnected:
+				return
+			}
+		}
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// RegisterRoutes registers the routes for the order event handler. They must be
+// registered with StreamAuthMiddleware.
+func (h *OrderEventHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/orders/:id/events", h.StreamOrderEvents)
+	router.GET("/restaurants/:id/orders/live", h.RestaurantOrdersSocket)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strconv"
 	"tumdum_backend/business"
+	"tumdum_backend/middleware"
 	"tumdum_backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -33,6 +34,9 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
+	// Orders are always placed on behalf of the authenticated user
+	order.UserID = middleware.GetUserID(c)
+
 	if err := h.orderService.CreateOrder(&order); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -61,27 +65,81 @@ func (h *OrderHandler) GetOrderByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
 		return
 	}
+	if !h.orderService.CanViewOrder(order, currentActor(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
 	c.JSON(http.StatusOK, order)
 }
 
 // @Summary Get user's order history
-// @Description Get all orders for a specific user
+// @Description Get all orders for a specific user. Customers can only read their own history.
 // @Tags orders
 // @Produce json
-// @Param user_id path int true "User ID"
-// @Success 200 {array} models.Order
+// @Param id path int true "User ID"
+// @Param status query string false "Comma-separated statuses to include"
+// @Param restaurant_id query int false "Filter by restaurant"
+// @Param created_from query string false "Only orders created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Only orders created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param upcoming query boolean false "Only active orders scheduled for a future slot, soonest first"
+// @Param sort query string false "Sort field (id, created_at, updated_at, status, total_amount, scheduled_for), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Order]
+// @Failure 403 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Router /users/{user_id}/orders [get]
+// @Router /users/{id}/orders [get]
 func (h *OrderHandler) GetUserOrders(c *gin.Context) {
-	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	// The path parameter is named "id" to share the /users/:id route prefix
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
 
-	orders, err := h.orderService.GetOrdersByUserID(uint(userID))
+	actor := currentActor(c)
+	if !actor.IsAdmin() && uint(userID) != actor.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
+	h.listUserOrders(c, uint(userID))
+}
+
+// @Summary Get my order history
+// @Description Get the orders of the authenticated user
+// @Tags orders
+// @Produce json
+// @Param status query string false "Comma-separated statuses to include"
+// @Param restaurant_id query int false "Filter by restaurant"
+// @Param created_from query string false "Only orders created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Only orders created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param upcoming query boolean false "Only active orders scheduled for a future slot, soonest first"
+// @Param sort query string false "Sort field (id, created_at, updated_at, status, total_amount, scheduled_for), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Order]
+// @Failure 400 {object} map[string]string
+// @Router /me/orders [get]
+func (h *OrderHandler) GetMyOrders(c *gin.Context) {
+	h.listUserOrders(c, middleware.GetUserID(c))
+}
+
+func (h *OrderHandler) listUserOrders(c *gin.Context, userID uint) {
+	filter, err := parseOrderFilter(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "orders not found"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := h.orderService.GetOrdersByUserID(userID, filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, orders)
@@ -93,7 +151,7 @@ func (h *OrderHandler) GetUserOrders(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Order ID"
-// @Param status body string true "New status"
+// @Param status body object true "New status and optional reason"
 // @Success 200 {object} models.Order
 // @Failure 400 {object} map[string]string
 // @Router /orders/{id}/status [put]
@@ -105,15 +163,16 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	}
 
 	var status struct {
-		Status models.OrderStatus `json:"status"`
+		Status models.OrderStatus `json:"status" binding:"required"`
+		Reason string             `json:"reason"`
 	}
 	if err := c.ShouldBindJSON(&status); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := h.orderService.UpdateOrderStatus(uint(id), status.Status); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.orderService.UpdateOrderStatus(uint(id), status.Status, status.Reason, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -125,15 +184,67 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, order)
 }
 
+// @Summary Get order timeline
+// @Description Get the status history of an order, oldest first
+// @Tags orders
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.OrderStatusEvent
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/timeline [get]
+func (h *OrderHandler) GetOrderTimeline(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	events, err := h.orderService.GetOrderTimeline(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
 // @Summary Get all orders
-// @Description Get all orders
+// @Description Get a page of all orders
 // @Tags orders
 // @Produce json
-// @Success 200 {array} models.Order
+// @Param user_id query int false "Filter by customer"
+// @Param status query string false "Comma-separated statuses to include"
+// @Param restaurant_id query int false "Filter by restaurant"
+// @Param created_from query string false "Only orders created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Only orders created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param upcoming query boolean false "Only active orders scheduled for a future slot, soonest first"
+// @Param sort query string false "Sort field (id, created_at, updated_at, status, total_amount, scheduled_for), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Order]
 // @Failure 400 {object} map[string]string
 // @Router /orders [get]
 func (h *OrderHandler) GetAllOrders(c *gin.Context) {
-	orders, err := h.orderService.GetAllOrders()
+	filter, err := parseOrderFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		filter.UserID = uint(id)
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := h.orderService.GetAllOrders(filter, page)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -166,8 +277,8 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 	}
 
 	order.ID = uint(id)
-	if err := h.orderService.UpdateOrder(&order); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.orderService.UpdateOrder(&order, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -189,6 +300,17 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 		return
 	}
 
+	order, err := h.orderService.GetOrderByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "order not found"})
+		return
+	}
+	// Customers may only delete their own orders
+	if middleware.GetUserRole(c) != models.UserRoleAdmin && order.UserID != middleware.GetUserID(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return
+	}
+
 	if err := h.orderService.DeleteOrder(uint(id)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -197,14 +319,143 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Order deleted successfully"})
 }
 
+// @Summary Get delivery slots
+// @Description Get the bookable scheduled delivery slots of a restaurant on a day
+// @Tags orders
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param date query string true "Day in the restaurant's time zone (YYYY-MM-DD)"
+// @Success 200 {array} models.DeliverySlot
+// @Failure 400 {object} map[string]string
+// @Router /restaurants/{id}/slots [get]
+func (h *OrderHandler) GetDeliverySlots(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID"})
+		return
+	}
+
+	slots, err := h.orderService.GetDeliverySlots(uint(restaurantID), c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, slots)
+}
+
+// @Summary Get restaurant orders
+// @Description Get the orders of a restaurant. Only the restaurant's members and admins can read them.
+// @Tags orders
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param status query string false "Comma-separated statuses to include"
+// @Param created_from query string false "Only orders created at or after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param created_to query string false "Only orders created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param upcoming query boolean false "Only active orders scheduled for a future slot, soonest first"
+// @Param sort query string false "Sort field (id, created_at, updated_at, status, total_amount, scheduled_for), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Order]
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/orders [get]
+func (h *OrderHandler) GetRestaurantOrders(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID"})
+		return
+	}
+	filter, err := parseOrderFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, err := h.orderService.GetRestaurantOrders(uint(restaurantID), filter, page, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// @Summary Get kitchen queue
+// @Description Get the confirmed, preparing and ready orders of a restaurant, longest waiting first, with the seconds each has spent in its status. Only the restaurant's members and admins can read it.
+// @Tags orders
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {object} models.KitchenQueue
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/kitchen [get]
+func (h *OrderHandler) GetKitchenQueue(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID"})
+		return
+	}
+
+	queue, err := h.orderService.GetKitchenQueue(uint(restaurantID), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// @Summary Advance kitchen orders
+// @Description Move a batch of a restaurant's orders to their next kitchen status: confirmed orders start preparing and preparing orders become ready. Each order is moved on its own and its outcome reported.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param request body models.KitchenAdvanceRequest true "Orders to advance"
+// @Success 200 {array} models.KitchenAdvanceResult
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /restaurants/{id}/kitchen/advance [post]
+func (h *OrderHandler) AdvanceKitchenOrders(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID"})
+		return
+	}
+	var request models.KitchenAdvanceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.orderService.AdvanceKitchenOrders(uint(restaurantID), request.OrderIDs, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
 // RegisterRoutes registers the routes for the order handler
 func (h *OrderHandler) RegisterRoutes(router *gin.RouterGroup) {
 	orders := router.Group("/orders")
 	{
 		orders.POST("", h.CreateOrder)
-		orders.GET("", h.GetAllOrders)
+		orders.GET("", middleware.RequireRoles(models.UserRoleAdmin), h.GetAllOrders)
 		orders.GET("/:id", h.GetOrderByID)
 		orders.PUT("/:id", h.UpdateOrder)
+		orders.PUT("/:id/status", h.UpdateOrderStatus)
+		orders.GET("/:id/timeline", h.GetOrderTimeline)
 		orders.DELETE("/:id", h.DeleteOrder)
 	}
+
+	router.GET("/users/:id/orders", h.GetUserOrders)
+	router.GET("/me/orders", h.GetMyOrders)
+	router.GET("/restaurants/:id/slots", h.GetDeliverySlots)
+	router.GET("/restaurants/:id/orders", h.GetRestaurantOrders)
+	router.GET("/restaurants/:id/kitchen", h.GetKitchenQueue)
+	router.POST("/restaurants/:id/kitchen/advance", h.AdvanceKitchenOrders)
 }
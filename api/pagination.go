@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageRequest reads the cursor, limit and sort query parameters shared by list endpoints
+func parsePageRequest(c *gin.Context) (models.PageRequest, error) {
+	page := models.PageRequest{
+		Cursor: c.Query("cursor"),
+		Sort:   c.Query("sort"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 {
+			return page, errors.New("invalid limit")
+		}
+		page.Limit = value
+	}
+	return page, nil
+}
+
+// parseBoolQuery reads an optional boolean query parameter
+func parseBoolQuery(c *gin.Context, key string) (*bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+	return &parsed, nil
+}
+
+// parseTimeQuery reads an optional RFC 3339 timestamp or YYYY-MM-DD date query parameter
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s", key)
+}
+
+// parseOrderFilter reads the status, restaurant_id, created_from, created_to and upcoming query parameters
+func parseOrderFilter(c *gin.Context) (models.OrderFilter, error) {
+	var filter models.OrderFilter
+	if statuses := c.Query("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			filter.Statuses = append(filter.Statuses, models.OrderStatus(strings.ToUpper(strings.TrimSpace(status))))
+		}
+	}
+	if restaurantID := c.Query("restaurant_id"); restaurantID != "" {
+		id, err := strconv.ParseUint(restaurantID, 10, 32)
+		if err != nil {
+			return filter, errors.New("invalid restaurant_id")
+		}
+		filter.RestaurantID = uint(id)
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseTimeQuery(c, "created_from"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseTimeQuery(c, "created_to"); err != nil {
+		return filter, err
+	}
+	upcoming, err := parseBoolQuery(c, "upcoming")
+	if err != nil {
+		return filter, err
+	}
+	filter.Upcoming = upcoming != nil && *upcoming
+	return filter, nil
+}
+
+// parseDishListQuery reads the filter and page parameters of the dish list endpoints
+func parseDishListQuery(c *gin.Context) (models.DishFilter, models.PageRequest, error) {
+	filter := models.DishFilter{Category: c.Query("category")}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		return filter, page, err
+	}
+	if menuCategoryID := c.Query("menu_category_id"); menuCategoryID != "" {
+		id, err := strconv.ParseUint(menuCategoryID, 10, 32)
+		if err != nil {
+			return filter, page, errors.New("invalid menu_category_id")
+		}
+		filter.MenuCategoryID = uint(id)
+	}
+	filter.IsAvailable, err = parseBoolQuery(c, "is_available")
+	return filter, page, err
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *business.PaymentService
+}
+
+func NewPaymentHandler(paymentService *business.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// @Summary Pay for an order
+// @Description Authorize the order total on a payment method. The restaurant can only confirm the order once a payment is authorized; the amount is captured on delivery.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param payment body models.PaymentRequest true "Payment method"
+// @Success 201 {object} models.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 402 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Router /orders/{id}/payments [post]
+func (h *PaymentHandler) PayOrder(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	var request models.PaymentRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := h.paymentService.PayOrder(uint(id), &request, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	if payment.Status == models.PaymentStatusFailed {
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": "payment declined", "payment": payment})
+		return
+	}
+	c.JSON(http.StatusCreated, payment)
+}
+
+// @Summary Get order payments
+// @Description Get the payment attempts of an order
+// @Tags payments
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /orders/{id}/payments [get]
+func (h *PaymentHandler) GetPayments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	payments, err := h.paymentService.GetPayments(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, payments)
+}
+
+// @Summary Payment provider webhook
+// @Description Receive a payment status change from the payment provider. The raw body must be signed in the X-Payment-Signature header.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "Payload signature"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /payments/webhook [post]
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.paymentService.HandleWebhook(payload, c.GetHeader("X-Payment-Signature")); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, business.ErrInvalidWebhookSignature) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook processed successfully"})
+}
+
+// RegisterRoutes registers the routes for the payment handler
+func (h *PaymentHandler) RegisterRoutes(router *gin.RouterGroup) {
+	// Public route, authenticated by the provider's signature
+	router.POST("/payments/webhook", h.Webhook)
+
+	protected := router.Group("")
+	protected.Use(middleware.AuthMiddleware())
+	{
+		protected.POST("/orders/:id/payments", h.PayOrder)
+		protected.GET("/orders/:id/payments", h.GetPayments)
+	}
+}
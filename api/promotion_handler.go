@@ -0,0 +1,173 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionService *business.PromotionService
+}
+
+func NewPromotionHandler(promotionService *business.PromotionService) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+// @Summary Get all promotions
+// @Description Get a page of promotions (admin only)
+// @Tags promotions
+// @Produce json
+// @Param code query string false "Filter by promo code"
+// @Param restaurant_id query int false "Filter by restaurant"
+// @Param is_active query boolean false "Filter by active status"
+// @Param sort query string false "Sort field (id, code, created_at, starts_at, ends_at), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Promotion]
+// @Failure 400 {object} map[string]string
+// @Router /promotions [get]
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := models.PromotionFilter{Code: c.Query("code")}
+	if restaurantID := c.Query("restaurant_id"); restaurantID != "" {
+		id, err := strconv.ParseUint(restaurantID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant_id"})
+			return
+		}
+		filter.RestaurantID = uint(id)
+	}
+	if filter.IsActive, err = parseBoolQuery(c, "is_active"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	promotions, err := h.promotionService.GetPromotions(filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, promotions)
+}
+
+// @Summary Get promotion by ID
+// @Description Get a promotion (admin only)
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} models.Promotion
+// @Failure 404 {object} map[string]string
+// @Router /promotions/{id} [get]
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion ID"})
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+// @Summary Create promotion
+// @Description Create a promo code (admin only). Without a restaurant_id it applies to every restaurant.
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param promotion body models.Promotion true "Promotion"
+// @Success 201 {object} models.Promotion
+// @Failure 400 {object} map[string]string
+// @Router /promotions [post]
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var promotion models.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.promotionService.CreatePromotion(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, promotion)
+}
+
+// @Summary Update promotion
+// @Description Update a promotion (admin only)
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Param promotion body models.Promotion true "Promotion"
+// @Success 200 {object} models.Promotion
+// @Failure 400 {object} map[string]string
+// @Router /promotions/{id} [put]
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion ID"})
+		return
+	}
+
+	var promotion models.Promotion
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	promotion.ID = uint(id)
+
+	if err := h.promotionService.UpdatePromotion(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, promotion)
+}
+
+// @Summary Delete promotion
+// @Description Delete a promotion (admin only). Orders keep their discount lines.
+// @Tags promotions
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /promotions/{id} [delete]
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid promotion ID"})
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Promotion deleted successfully"})
+}
+
+// RegisterRoutes registers the routes for the promotion handler
+func (h *PromotionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	promotions := router.Group("/promotions")
+	promotions.Use(middleware.RequireRoles(models.UserRoleAdmin))
+	{
+		promotions.GET("", h.GetPromotions)
+		promotions.GET("/:id", h.GetPromotion)
+		promotions.POST("", h.CreatePromotion)
+		promotions.PUT("/:id", h.UpdatePromotion)
+		promotions.DELETE("/:id", h.DeletePromotion)
+	}
+}
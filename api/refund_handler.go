@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RefundHandler struct {
+	refundService *business.RefundService
+}
+
+func NewRefundHandler(refundService *business.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+// @Summary Request a refund
+// @Description Refund or cancel items of an order, or the whole order when no items are given. Requests by the restaurant's owners and managers or an admin are approved at once.
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param refund body models.RefundRequest true "Items and reason"
+// @Success 201 {object} models.Refund
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /orders/{id}/refunds [post]
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	var request models.RefundRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := h.refundService.RequestRefund(uint(id), &request, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, refund)
+}
+
+// @Summary Get order refunds
+// @Description Get the refunds of an order
+// @Tags refunds
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {array} models.Refund
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /orders/{id}/refunds [get]
+func (h *RefundHandler) GetRefunds(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	refunds, err := h.refundService.GetRefunds(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
+// @Summary Approve a refund
+// @Description Approve a requested refund. The refunded items are taken off the order total and captured money is paid back.
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param refund_id path int true "Refund ID"
+// @Param review body models.RefundReview false "Review note"
+// @Success 200 {object} models.Refund
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /orders/{id}/refunds/{refund_id}/approve [post]
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	h.reviewRefund(c, h.refundService.ApproveRefund)
+}
+
+// @Summary Reject a refund
+// @Description Reject a requested refund
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param refund_id path int true "Refund ID"
+// @Param review body models.RefundReview false "Review note"
+// @Success 200 {object} models.Refund
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /orders/{id}/refunds/{refund_id}/reject [post]
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	h.reviewRefund(c, h.refundService.RejectRefund)
+}
+
+func (h *RefundHandler) reviewRefund(c *gin.Context, review func(orderID, refundID uint, review *models.RefundReview, actor business.Actor) (*models.Refund, error)) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+	refundID, err := strconv.ParseUint(c.Param("refund_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid refund ID"})
+		return
+	}
+
+	var request models.RefundReview
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	refund, err := review(uint(id), uint(refundID), &request, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, refund)
+}
+
+// RegisterRoutes registers the routes for the refund handler
+func (h *RefundHandler) RegisterRoutes(router *gin.RouterGroup) {
+	refunds := router.Group("/orders/:id/refunds")
+	{
+		refunds.POST("", h.RequestRefund)
+		refunds.GET("", h.GetRefunds)
+		refunds.POST("/:refund_id/approve", h.ApproveRefund)
+		refunds.POST("/:refund_id/reject", h.RejectRefund)
+	}
+}
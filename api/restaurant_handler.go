@@ -8,8 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
-	"time"
 	"tumdum_backend/business"
+	"tumdum_backend/middleware"
 	"tumdum_backend/models"
 
 	"github.com/gin-gonic/gin"
@@ -45,7 +45,7 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 		return
 	}
 
-	if err := h.restaurantService.CreateRestaurant(&restaurant); err != nil {
+	if err := h.restaurantService.CreateRestaurant(&restaurant, currentActor(c)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -54,21 +54,92 @@ func (h *RestaurantHandler) CreateRestaurant(c *gin.Context) {
 }
 
 // @Summary Get all restaurants
-// @Description Get a list of all restaurants with optional filtering
+// @Description Get a page of restaurants with optional filtering and sorting
 // @Tags restaurants
 // @Accept json
 // @Produce json
 // @Param cuisine query string false "Filter by cuisine type"
-// @Param rating query number false "Filter by minimum rating"
+// @Param city query string false "Filter by city"
+// @Param min_rating query number false "Filter by minimum rating"
 // @Param is_active query boolean false "Filter by active status"
-// @Success 200 {array} models.Restaurant
+// @Param sort query string false "Sort field (id, name, rating, created_at), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Restaurant]
+// @Failure 400 {object} ErrorResponse
 // @Router /restaurants [get]
 func (h *RestaurantHandler) GetAllRestaurants(c *gin.Context) {
-	cuisine := c.Query("cuisine")
-	isActive := c.Query("is_active")
-	city := c.Query("city")
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := models.RestaurantFilter{
+		Cuisine: c.Query("cuisine"),
+		City:    c.Query("city"),
+	}
+	if filter.IsActive, err = parseBoolQuery(c, "is_active"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if minRating := c.Query("min_rating"); minRating != "" {
+		rating, err := strconv.ParseFloat(minRating, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_rating"})
+			return
+		}
+		filter.MinRating = &rating
+	}
 
-	restaurants, err := h.restaurantService.GetAllRestaurants(cuisine, isActive, city)
+	restaurants, err := h.restaurantService.GetAllRestaurants(filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, restaurants)
+}
+
+// @Summary Get nearby restaurants
+// @Description Get active restaurants within a radius of a point, nearest first
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param radius query number false "Search radius in km (default 5, max 50)"
+// @Param limit query int false "Maximum number of restaurants (default 20, max 100)"
+// @Success 200 {array} models.Restaurant
+// @Failure 400 {object} ErrorResponse
+// @Router /restaurants/nearby [get]
+func (h *RestaurantHandler) GetNearbyRestaurants(c *gin.Context) {
+	lat, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lat"})
+		return
+	}
+	lng, err := strconv.ParseFloat(c.Query("lng"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid lng"})
+		return
+	}
+
+	var radius float64
+	if value := c.Query("radius"); value != "" {
+		if radius, err = strconv.ParseFloat(value, 64); err != nil || radius <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid radius"})
+			return
+		}
+	}
+
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	restaurants, err := h.restaurantService.GetNearbyRestaurants(lat, lng, radius, page.Limit)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -140,8 +211,8 @@ func (h *RestaurantHandler) UpdateRestaurant(c *gin.Context) {
 	}
 
 	restaurant.ID = uint(restaurantID)
-	if err := h.restaurantService.UpdateRestaurant(&restaurant); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.restaurantService.UpdateRestaurant(&restaurant, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -181,11 +252,6 @@ func (h *RestaurantHandler) uploadImage(file *multipart.FileHeader, imageType st
 	return fmt.Sprintf("/images/%s", filename), nil
 }
 
-// Helper function to parse time
-func parseTime(timeStr string) (time.Time, error) {
-	return time.Parse("15:04:05", timeStr)
-}
-
 // @Summary Delete restaurant
 // @Description Delete a restaurant
 // @Tags restaurants
@@ -218,12 +284,18 @@ func (h *RestaurantHandler) DeleteRestaurant(c *gin.Context) {
 }
 
 // @Summary Get restaurant dishes
-// @Description Get all dishes for a specific restaurant
+// @Description Get a page of dishes for a specific restaurant
 // @Tags restaurants
 // @Accept json
 // @Produce json
 // @Param id path int true "Restaurant ID"
-// @Success 200 {array} models.Dish
+// @Param category query string false "Filter by category"
+// @Param menu_category_id query int false "Filter by menu category"
+// @Param is_available query boolean false "Filter by availability"
+// @Param sort query string false "Sort field (id, name, price, category, display_order, created_at), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Dish]
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /restaurants/{id}/dishes [get]
@@ -240,24 +312,248 @@ func (h *RestaurantHandler) GetRestaurantDishes(c *gin.Context) {
 		return
 	}
 
-	dishes, err := h.dishService.GetDishesByRestaurantID(uint(restaurantID))
+	filter, page, err := parseDishListQuery(c)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "restaurant not found"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dishes, err := h.dishService.GetDishesByRestaurantID(uint(restaurantID), filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, dishes)
 }
 
+// @Summary Get restaurant opening hours
+// @Description Get the weekly opening hours, upcoming closures and open state of a restaurant
+// @Tags restaurants
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {object} models.RestaurantSchedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /restaurants/{id}/hours [get]
+func (h *RestaurantHandler) GetSchedule(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	schedule, err := h.restaurantService.GetSchedule(uint(restaurantID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Update restaurant opening hours
+// @Description Replace the weekly opening hours of a restaurant
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param hours body []models.OpeningHours true "Opening hour intervals"
+// @Success 200 {object} models.RestaurantSchedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /restaurants/{id}/hours [put]
+func (h *RestaurantHandler) UpdateOpeningHours(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	var hours []models.OpeningHours
+	if err := c.ShouldBindJSON(&hours); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	schedule, err := h.restaurantService.UpdateOpeningHours(uint(restaurantID), hours, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// @Summary Add restaurant closure
+// @Description Close a restaurant for a holiday or other exception
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param closure body models.RestaurantClosure true "Closure period"
+// @Success 201 {object} models.RestaurantClosure
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /restaurants/{id}/closures [post]
+func (h *RestaurantHandler) AddClosure(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	var closure models.RestaurantClosure
+	if err := c.ShouldBindJSON(&closure); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.restaurantService.AddClosure(uint(restaurantID), &closure, currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, closure)
+}
+
+// @Summary Remove restaurant closure
+// @Description Remove a holiday or other exceptional closure
+// @Tags restaurants
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param closure_id path int true "Closure ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /restaurants/{id}/closures/{closure_id} [delete]
+func (h *RestaurantHandler) RemoveClosure(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	closureID, err := strconv.ParseUint(c.Param("closure_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid closure ID format"})
+		return
+	}
+
+	if err := h.restaurantService.RemoveClosure(uint(restaurantID), uint(closureID), currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "closure removed successfully"})
+}
+
+// @Summary Add restaurant member
+// @Description Add an existing user to a restaurant as owner, manager or kitchen staff
+// @Tags restaurants
+// @Accept json
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param invite body models.RestaurantMemberInvite true "Member invite"
+// @Success 201 {object} models.RestaurantMember
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /restaurants/{id}/members [post]
+func (h *RestaurantHandler) AddMember(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	var invite models.RestaurantMemberInvite
+	if err := c.ShouldBindJSON(&invite); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.restaurantService.AddMember(uint(restaurantID), &invite, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, member)
+}
+
+// @Summary Get restaurant members
+// @Description Get all users who manage a restaurant
+// @Tags restaurants
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Success 200 {array} models.RestaurantMember
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /restaurants/{id}/members [get]
+func (h *RestaurantHandler) GetMembers(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	members, err := h.restaurantService.GetMembers(uint(restaurantID), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// @Summary Remove restaurant member
+// @Description Remove a user from a restaurant
+// @Tags restaurants
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param member_id path int true "Member ID"
+// @Success 200 {object} SuccessResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /restaurants/{id}/members/{member_id} [delete]
+func (h *RestaurantHandler) RemoveMember(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID format"})
+		return
+	}
+
+	memberID, err := strconv.ParseUint(c.Param("member_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid member ID format"})
+		return
+	}
+
+	if err := h.restaurantService.RemoveMember(uint(restaurantID), uint(memberID), currentActor(c)); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "member removed successfully"})
+}
+
 // RegisterRoutes registers the routes for the restaurant handler
 func (h *RestaurantHandler) RegisterRoutes(router *gin.RouterGroup) {
 	restaurants := router.Group("/restaurants")
 	{
-		restaurants.POST("", h.CreateRestaurant)
+		restaurants.POST("", middleware.RequireRoles(models.UserRoleOwner, models.UserRoleAdmin), h.CreateRestaurant)
 		restaurants.GET("", h.GetAllRestaurants)
+		restaurants.GET("/nearby", h.GetNearbyRestaurants)
 		restaurants.GET("/:id", h.GetRestaurantByID)
 		restaurants.PUT("/:id", h.UpdateRestaurant)
-		restaurants.DELETE("/:id", h.DeleteRestaurant)
+		restaurants.DELETE("/:id", middleware.RequireRoles(models.UserRoleAdmin), h.DeleteRestaurant)
 		restaurants.GET("/:id/dishes", h.GetRestaurantDishes)
+		restaurants.POST("/:id/members", h.AddMember)
+		restaurants.GET("/:id/members", h.GetMembers)
+		restaurants.DELETE("/:id/members/:member_id", h.RemoveMember)
+		restaurants.GET("/:id/hours", h.GetSchedule)
+		restaurants.PUT("/:id/hours", h.UpdateOpeningHours)
+		restaurants.POST("/:id/closures", h.AddClosure)
+		restaurants.DELETE("/:id/closures/:closure_id", h.RemoveClosure)
 	}
 }
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"tumdum_backend/business"
+	"tumdum_backend/middleware"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewHandler struct {
+	reviewService *business.ReviewService
+}
+
+func NewReviewHandler(reviewService *business.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// @Summary Review an order
+// @Description Rate a delivered order, and optionally the dishes in it. Only the customer who placed the order can review it, once.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Order ID"
+// @Param review body models.ReviewRequest true "Ratings and comment"
+// @Success 201 {object} models.Review
+// @Failure 400 {object} map[string]string
+// @Router /orders/{id}/review [post]
+func (h *ReviewHandler) CreateReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	var request models.ReviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.CreateReview(uint(id), &request, currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, review)
+}
+
+// @Summary Get order review
+// @Description Get the review of an order
+// @Tags reviews
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} models.Review
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /orders/{id}/review [get]
+func (h *ReviewHandler) GetOrderReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	review, err := h.reviewService.GetOrderReview(uint(id), currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// @Summary Get restaurant reviews
+// @Description Get a page of the reviews of a restaurant, newest first. Hidden reviews are left out.
+// @Tags reviews
+// @Produce json
+// @Param id path int true "Restaurant ID"
+// @Param sort query string false "Sort field (id, created_at, rating), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Review]
+// @Failure 400 {object} map[string]string
+// @Router /restaurants/{id}/reviews [get]
+func (h *ReviewHandler) GetRestaurantReviews(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant ID"})
+		return
+	}
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviews, err := h.reviewService.GetRestaurantReviews(uint(id), page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// @Summary Get all reviews
+// @Description Get a page of reviews in any status for moderation (admin only)
+// @Tags reviews
+// @Produce json
+// @Param status query string false "Comma-separated statuses to include (PUBLISHED, FLAGGED, HIDDEN)"
+// @Param restaurant_id query int false "Filter by restaurant"
+// @Param sort query string false "Sort field (id, created_at, rating), prefix with - for descending"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page's next_cursor"
+// @Success 200 {object} models.Page[models.Review]
+// @Failure 400 {object} map[string]string
+// @Router /reviews [get]
+func (h *ReviewHandler) GetReviews(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var filter models.ReviewFilter
+	if statuses := c.Query("status"); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			filter.Statuses = append(filter.Statuses, models.ReviewStatus(strings.ToUpper(strings.TrimSpace(status))))
+		}
+	}
+	if restaurantID := c.Query("restaurant_id"); restaurantID != "" {
+		id, err := strconv.ParseUint(restaurantID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid restaurant_id"})
+			return
+		}
+		filter.RestaurantID = uint(id)
+	}
+
+	reviews, err := h.reviewService.GetReviews(filter, page)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reviews)
+}
+
+// @Summary Reply to a review
+// @Description Set the restaurant's public answer to a review. Requires member role OWNER or MANAGER; an empty reply removes the answer.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param reply body models.ReviewReply true "Reply"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /reviews/{id}/reply [put]
+func (h *ReviewHandler) ReplyToReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+
+	var reply models.ReviewReply
+	if err := c.ShouldBindJSON(&reply); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.ReplyToReview(uint(id), reply.Reply, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// @Summary Flag a review
+// @Description Report a review to the moderators. It stays visible until an admin hides it.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param flag body models.ReviewFlag true "Reason"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} map[string]string
+// @Router /reviews/{id}/flag [post]
+func (h *ReviewHandler) FlagReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+
+	var flag models.ReviewFlag
+	if err := c.ShouldBindJSON(&flag); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.FlagReview(uint(id), flag.Reason, currentActor(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// @Summary Moderate a review
+// @Description Publish or hide a review (admin only). Hidden reviews no longer count towards the ratings of the restaurant and its dishes.
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Param id path int true "Review ID"
+// @Param moderation body models.ReviewModeration true "New status and note"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} map[string]string
+// @Router /reviews/{id}/moderation [put]
+func (h *ReviewHandler) ModerateReview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid review ID"})
+		return
+	}
+
+	var moderation models.ReviewModeration
+	if err := c.ShouldBindJSON(&moderation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.ModerateReview(uint(id), &moderation, currentActor(c))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, review)
+}
+
+// RegisterRoutes registers the routes for the review handler
+func (h *ReviewHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("/orders/:id/review", h.CreateReview)
+	router.GET("/orders/:id/review", h.GetOrderReview)
+	router.GET("/restaurants/:id/reviews", h.GetRestaurantReviews)
+
+	reviews := router.Group("/reviews")
+	{
+		reviews.GET("", middleware.RequireRoles(models.UserRoleAdmin), h.GetReviews)
+		reviews.PUT("/:id/reply", h.ReplyToReview)
+		reviews.POST("/:id/flag", h.FlagReview)
+		reviews.PUT("/:id/moderation", middleware.RequireRoles(models.UserRoleAdmin), h.ModerateReview)
+	}
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"tumdum_backend/business"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService *business.SearchService
+}
+
+func NewSearchHandler(searchService *business.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// @Summary Search restaurants and dishes
+// @Description Full-text search over restaurant and dish names, descriptions, cuisines and categories with prefix and typo-tolerant matching
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search text (at least 2 characters)"
+// @Param type query string false "What to search (all, restaurants, dishes)"
+// @Param limit query int false "Maximum results per type (default 10, max 50)"
+// @Success 200 {object} models.SearchResults
+// @Failure 400 {object} map[string]string
+// @Router /search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.searchService.Search(c.Query("q"), c.Query("type"), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// RegisterRoutes registers the routes for the search handler
+func (h *SearchHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/search", h.Search)
+}
@@ -18,6 +18,15 @@ type Server struct {
 	dishService       *business.DishService
 	orderService      *business.OrderService
 	userService       *business.UserService
+	searchService     *business.SearchService
+	addressService    *business.AddressService
+	cartService       *business.CartService
+	menuService       *business.MenuService
+	paymentService    *business.PaymentService
+	refundService     *business.RefundService
+	promotionService  *business.PromotionService
+	deliveryService   *business.DeliveryService
+	reviewService     *business.ReviewService
 	config            *config.Config
 	imageHandler      *ImageHandler
 }
@@ -28,16 +37,36 @@ func NewServer(
 	dishService *business.DishService,
 	orderService *business.OrderService,
 	userService *business.UserService,
+	searchService *business.SearchService,
+	addressService *business.AddressService,
+	cartService *business.CartService,
+	menuService *business.MenuService,
+	paymentService *business.PaymentService,
+	refundService *business.RefundService,
+	promotionService *business.PromotionService,
+	deliveryService *business.DeliveryService,
+	reviewService *business.ReviewService,
 	config *config.Config,
 	imageHandler *ImageHandler,
 ) *Server {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(middleware.Logger(), gin.Recovery())
 
 	// Initialize handlers
 	restaurantHandler := NewRestaurantHandler(restaurantService, imageHandler, dishService)
 	dishHandler := NewDishHandler(dishService, imageHandler)
 	orderHandler := NewOrderHandler(orderService)
 	userHandler := NewUserHandler(userService)
+	searchHandler := NewSearchHandler(searchService)
+	addressHandler := NewAddressHandler(addressService)
+	cartHandler := NewCartHandler(cartService)
+	menuHandler := NewMenuHandler(menuService)
+	paymentHandler := NewPaymentHandler(paymentService)
+	refundHandler := NewRefundHandler(refundService)
+	promotionHandler := NewPromotionHandler(promotionService)
+	deliveryHandler := NewDeliveryHandler(deliveryService)
+	reviewHandler := NewReviewHandler(reviewService)
+	orderEventHandler := NewOrderEventHandler(orderService)
 
 	// Add CORS middleware
 	router.Use(func(c *gin.Context) {
@@ -60,6 +89,7 @@ func NewServer(
 	// Public routes
 	userHandler.RegisterRoutes(api)
 	imageHandler.RegisterRoutes(api)
+	paymentHandler.RegisterRoutes(api)
 
 	// Protected routes
 	protected := api.Group("")
@@ -68,6 +98,21 @@ func NewServer(
 		restaurantHandler.RegisterRoutes(protected)
 		dishHandler.RegisterRoutes(protected)
 		orderHandler.RegisterRoutes(protected)
+		searchHandler.RegisterRoutes(protected)
+		addressHandler.RegisterRoutes(protected)
+		cartHandler.RegisterRoutes(protected)
+		menuHandler.RegisterRoutes(protected)
+		refundHandler.RegisterRoutes(protected)
+		promotionHandler.RegisterRoutes(protected)
+		deliveryHandler.RegisterRoutes(protected)
+		reviewHandler.RegisterRoutes(protected)
+	}
+
+	// Live updates also accept the token as a query parameter
+	streams := api.Group("")
+	streams.Use(middleware.StreamAuthMiddleware())
+	{
+		orderEventHandler.RegisterRoutes(streams)
 	}
 
 	// Serve static files
@@ -79,6 +124,15 @@ func NewServer(
 		dishService:       dishService,
 		orderService:      orderService,
 		userService:       userService,
+		searchService:     searchService,
+		addressService:    addressService,
+		cartService:       cartService,
+		menuService:       menuService,
+		paymentService:    paymentService,
+		refundService:     refundService,
+		promotionService:  promotionService,
+		deliveryService:   deliveryService,
+		reviewService:     reviewService,
 		config:            config,
 		imageHandler:      imageHandler,
 	}
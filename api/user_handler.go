@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 	"tumdum_backend/auth"
 	"tumdum_backend/business"
 	"tumdum_backend/middleware"
@@ -53,6 +54,9 @@ func (h *UserHandler) Register(c *gin.Context) {
 		State:      register.State,
 		Country:    register.Country,
 		PostalCode: register.PostalCode,
+		Latitude:   register.Latitude,
+		Longitude:  register.Longitude,
+		Role:       models.UserRoleCustomer,
 	}
 
 	if err := h.userService.CreateUser(&user); err != nil {
@@ -61,7 +65,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, err := auth.GenerateToken(user.ID, user.Email)
+	token, err := auth.GenerateToken(user.ID, user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
@@ -105,7 +109,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, err := auth.GenerateToken(user.ID, user.Email)
+	token, err := auth.GenerateToken(user.ID, user.Email, user.Role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
 		return
@@ -124,10 +128,14 @@ func (h *UserHandler) Login(c *gin.Context) {
 // @Param id path string true "User ID"
 // @Success 200 {object} models.User
 // @Failure 404 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Security BearerAuth
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUser(c *gin.Context) {
 	id := c.Param("id")
+	if !authorizeUserAccess(c, id) {
+		return
+	}
 	user, err := h.userService.GetUser(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
@@ -146,10 +154,14 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // @Success 200 {object} models.User
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Security BearerAuth
 // @Router /users/{id} [put]
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
+	if !authorizeUserAccess(c, id) {
+		return
+	}
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -169,10 +181,14 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Param id path string true "User ID"
 // @Success 200 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Security BearerAuth
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
+	if !authorizeUserAccess(c, id) {
+		return
+	}
 	if err := h.userService.DeleteUser(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -180,6 +196,50 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
 }
 
+// @Summary Update user role
+// @Description Change the role of a user (admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param role body models.UserRoleUpdate true "New role"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Security BearerAuth
+// @Router /users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id := c.Param("id")
+	var update models.UserRoleUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.UpdateUserRole(id, update.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// authorizeUserAccess checks that the caller is the user with the given ID or an
+// admin, and writes the error response otherwise
+func authorizeUserAccess(c *gin.Context, id string) bool {
+	userID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return false
+	}
+	actor := currentActor(c)
+	if !actor.IsAdmin() && uint(userID) != actor.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return false
+	}
+	return true
+}
+
 // RegisterRoutes registers the user routes
 func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
 	users := router.Group("/users")
@@ -195,6 +255,7 @@ func (h *UserHandler) RegisterRoutes(router *gin.RouterGroup) {
 			protected.GET("/:id", h.GetUser)
 			protected.PUT("/:id", h.UpdateUser)
 			protected.DELETE("/:id", h.DeleteUser)
+			protected.PUT("/:id/role", middleware.RequireRoles(models.UserRoleAdmin), h.UpdateUserRole)
 		}
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 	"tumdum_backend/config"
+	"tumdum_backend/models"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
@@ -23,8 +24,9 @@ func Initialize(cfg *config.JWTConfig) {
 
 // Claims represents the JWT claims
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID uint            `json:"user_id"`
+	Email  string          `json:"email"`
+	Role   models.UserRole `json:"role"`
 	jwt.RegisteredClaims
 }
 
@@ -41,7 +43,7 @@ func CheckPasswordHash(password, hash string) bool {
 }
 
 // GenerateToken generates a new JWT token
-func GenerateToken(userID uint, email string) (string, error) {
+func GenerateToken(userID uint, email string, role models.UserRole) (string, error) {
 	if jwtConfig == nil || jwtConfig.Secret == "" {
 		return "", fmt.Errorf("JWT configuration not initialized")
 	}
@@ -50,6 +52,7 @@ func GenerateToken(userID uint, email string) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
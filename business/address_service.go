@@ -0,0 +1,74 @@
+package business
+
+import (
+	"errors"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+type AddressService struct {
+	addressDAO *dao.UserAddressDAO
+}
+
+func NewAddressService(addressDAO *dao.UserAddressDAO) *AddressService {
+	return &AddressService{addressDAO: addressDAO}
+}
+
+func (s *AddressService) CreateAddress(userID uint, address *models.UserAddress) error {
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+	address.ID = 0
+	address.UserID = userID
+
+	// The first saved address becomes the default
+	if !address.IsDefault {
+		if _, err := s.addressDAO.GetDefault(userID); err != nil {
+			address.IsDefault = true
+		}
+	}
+	return s.addressDAO.Create(address)
+}
+
+func (s *AddressService) GetAddresses(userID uint) ([]models.UserAddress, error) {
+	return s.addressDAO.GetByUserID(userID)
+}
+
+// GetAddress returns one of the user's addresses
+func (s *AddressService) GetAddress(userID, addressID uint) (*models.UserAddress, error) {
+	address, err := s.addressDAO.GetByID(addressID)
+	if err != nil || address.UserID != userID {
+		return nil, errors.New("address not found")
+	}
+	return address, nil
+}
+
+func (s *AddressService) UpdateAddress(userID uint, address *models.UserAddress) error {
+	existing, err := s.GetAddress(userID, address.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateAddress(address); err != nil {
+		return err
+	}
+	address.UserID = userID
+	address.CreatedAt = existing.CreatedAt
+	return s.addressDAO.Update(address)
+}
+
+func (s *AddressService) DeleteAddress(userID, addressID uint) error {
+	if _, err := s.GetAddress(userID, addressID); err != nil {
+		return err
+	}
+	return s.addressDAO.Delete(addressID)
+}
+
+func validateAddress(address *models.UserAddress) error {
+	if address.Address == "" {
+		return errors.New("address is required")
+	}
+	if !models.ValidCoordinates(address.Latitude, address.Longitude) {
+		return errors.New("invalid coordinates")
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package business
+
+import (
+	"errors"
+	"log"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+type CartService struct {
+	uow          *dao.UnitOfWork
+	cartDAO      *dao.CartDAO
+	dishDAO      *dao.DishDAO
+	orderService *OrderService
+}
+
+func NewCartService(uow *dao.UnitOfWork, cartDAO *dao.CartDAO, dishDAO *dao.DishDAO, orderService *OrderService) *CartService {
+	return &CartService{
+		uow:          uow,
+		cartDAO:      cartDAO,
+		dishDAO:      dishDAO,
+		orderService: orderService,
+	}
+}
+
+// GetCart returns the user's cart priced with the current dish prices
+func (s *CartService) GetCart(userID uint) (*models.Cart, error) {
+	cart, err := s.cartDAO.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	priceCart(cart)
+	return cart, nil
+}
+
+// AddItem adds a dish to the cart, increasing the quantity if it is already there
+// with the same modifiers.
+// Dishes of another restaurant are rejected until the cart is cleared.
+func (s *CartService) AddItem(userID uint, request *models.CartItemRequest) (*models.Cart, error) {
+	if request.Quantity <= 0 {
+		return nil, errors.New("item quantity must be positive")
+	}
+	dish, err := s.dishDAO.GetByID(request.DishID)
+	if err != nil {
+		return nil, errors.New("dish not found")
+	}
+	if !dish.IsAvailable {
+		return nil, errors.New("dish is not available")
+	}
+	if _, err := selectModifiers(dish.ModifierGroups, request.ModifierOptionIDs); err != nil {
+		return nil, err
+	}
+
+	// The cart row is locked so concurrent additions cannot mix restaurants
+	err = s.uow.Do(func(tx *dao.Tx) error {
+		cart, err := tx.Carts.GetOrCreateByUserIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		if len(cart.Items) > 0 && cart.RestaurantID != nil && *cart.RestaurantID != dish.RestaurantID {
+			return errors.New("cart contains dishes from another restaurant")
+		}
+
+		if item := findCartItemByDish(cart, dish.ID, request.ModifierOptionIDs); item != nil {
+			err = tx.Carts.UpdateItemQuantity(item.ID, item.Quantity+request.Quantity)
+		} else {
+			item := &models.CartItem{
+				CartID:   cart.ID,
+				DishID:   dish.ID,
+				Quantity: request.Quantity,
+			}
+			for _, optionID := range request.ModifierOptionIDs {
+				item.Modifiers = append(item.Modifiers, models.CartItemModifier{ModifierOptionID: optionID})
+			}
+			err = tx.Carts.CreateItem(item)
+		}
+		if err != nil {
+			return err
+		}
+
+		if cart.RestaurantID == nil || *cart.RestaurantID != dish.RestaurantID {
+			restaurantID := dish.RestaurantID
+			return tx.Carts.SetRestaurant(cart.ID, &restaurantID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+// UpdateItemQuantity changes the quantity of a cart item, removing it at zero
+func (s *CartService) UpdateItemQuantity(userID, itemID uint, quantity int) (*models.Cart, error) {
+	if quantity < 0 {
+		return nil, errors.New("item quantity cannot be negative")
+	}
+	if quantity == 0 {
+		return s.RemoveItem(userID, itemID)
+	}
+
+	cart, err := s.cartDAO.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if findCartItem(cart, itemID) == nil {
+		return nil, errors.New("cart item not found")
+	}
+	if err := s.cartDAO.UpdateItemQuantity(itemID, quantity); err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+func (s *CartService) RemoveItem(userID, itemID uint) (*models.Cart, error) {
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		cart, err := tx.Carts.GetOrCreateByUserIDForUpdate(userID)
+		if err != nil {
+			return err
+		}
+		if findCartItem(cart, itemID) == nil {
+			return errors.New("cart item not found")
+		}
+
+		// Removing the last item frees the cart for another restaurant
+		if len(cart.Items) == 1 {
+			return tx.Carts.Clear(cart.ID)
+		}
+		return tx.Carts.DeleteItem(itemID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+func (s *CartService) ClearCart(userID uint) (*models.Cart, error) {
+	cart, err := s.cartDAO.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cartDAO.Clear(cart.ID); err != nil {
+		return nil, err
+	}
+	return s.GetCart(userID)
+}
+
+// Checkout places an order for the cart's items through OrderService.CreateOrder,
+// which validates and prices them, and empties the cart once the order exists
+func (s *CartService) Checkout(userID uint, checkout *models.CartCheckout) (*models.Order, error) {
+	cart, err := s.cartDAO.GetOrCreateByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 || cart.RestaurantID == nil {
+		return nil, errors.New("cart is empty")
+	}
+
+	order := &models.Order{
+		UserID:            userID,
+		RestaurantID:      *cart.RestaurantID,
+		DeliveryAddressID: checkout.DeliveryAddressID,
+		DeliveryLatitude:  checkout.DeliveryLatitude,
+		DeliveryLongitude: checkout.DeliveryLongitude,
+		ScheduledFor:      checkout.ScheduledFor,
+		PromoCode:         checkout.PromoCode,
+		TipAmount:         checkout.TipAmount,
+	}
+	for _, item := range cart.Items {
+		if item.Dish.RestaurantID != *cart.RestaurantID {
+			return nil, errors.New("cart contains dishes from more than one restaurant")
+		}
+		orderItem := models.OrderItem{
+			DishID:   item.DishID,
+			Quantity: item.Quantity,
+		}
+		for _, modifier := range item.Modifiers {
+			optionID := modifier.ModifierOptionID
+			orderItem.Modifiers = append(orderItem.Modifiers, models.OrderItemModifier{ModifierOptionID: &optionID})
+		}
+		order.OrderItems = append(order.OrderItems, orderItem)
+	}
+
+	if err := s.orderService.CreateOrder(order); err != nil {
+		return nil, err
+	}
+
+	// The order is already placed, so a failure here only leaves a stale cart behind
+	if err := s.cartDAO.Clear(cart.ID); err != nil {
+		log.Printf("Failed to clear cart %d after checkout: %v", cart.ID, err)
+	}
+	return order, nil
+}
+
+// priceCart fills in the live unit prices, line totals and subtotal of the cart.
+// Items with an unavailable dish or modifier option are flagged and left out of the
+// subtotal.
+func priceCart(cart *models.Cart) {
+	cart.Subtotal = nil
+	if len(cart.Items) == 0 {
+		return
+	}
+
+	var subtotal *models.Money
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		item.UnitPrice = item.Dish.Price
+		item.IsAvailable = item.Dish.IsAvailable &&
+			cart.RestaurantID != nil && item.Dish.RestaurantID == *cart.RestaurantID
+		for _, modifier := range item.Modifiers {
+			item.UnitPrice = item.UnitPrice.Add(modifier.ModifierOption.Price)
+			item.IsAvailable = item.IsAvailable && modifier.ModifierOption.IsAvailable
+		}
+		item.LineTotal = item.UnitPrice.Multiply(int64(item.Quantity))
+		if !item.IsAvailable {
+			continue
+		}
+
+		if subtotal == nil {
+			total := models.NewMoney(0, item.LineTotal.Currency)
+			subtotal = &total
+		}
+		if subtotal.SameCurrency(item.LineTotal) {
+			*subtotal = subtotal.Add(item.LineTotal)
+		}
+	}
+	cart.Subtotal = subtotal
+}
+
+func findCartItem(cart *models.Cart, itemID uint) *models.CartItem {
+	for i := range cart.Items {
+		if cart.Items[i].ID == itemID {
+			return &cart.Items[i]
+		}
+	}
+	return nil
+}
+
+// findCartItemByDish finds the cart item with the dish and exactly the given modifier options
+func findCartItemByDish(cart *models.Cart, dishID uint, optionIDs []uint) *models.CartItem {
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		if item.DishID != dishID || len(item.Modifiers) != len(optionIDs) {
+			continue
+		}
+		selected := make(map[uint]bool, len(item.Modifiers))
+		for _, modifier := range item.Modifiers {
+			selected[modifier.ModifierOptionID] = true
+		}
+		same := true
+		for _, id := range optionIDs {
+			same = same && selected[id]
+		}
+		if same {
+			return item
+		}
+	}
+	return nil
+}
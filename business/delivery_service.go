@@ -0,0 +1,328 @@
+package business
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+	"tumdum_backend/config"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// dispatchBatchSize bounds how many ready orders are offered to drivers per run
+const dispatchBatchSize = 100
+
+// DeliveryService manages drivers and the delivery of ready orders. Each ready
+// order is offered to the nearest available driver, one driver at a time, until
+// one accepts it; the driver then moves the order along until it is delivered.
+type DeliveryService struct {
+	uow       *dao.UnitOfWork
+	driverDAO *dao.DriverDAO
+	offerDAO  *dao.DeliveryOfferDAO
+	orderDAO  *dao.OrderDAO
+	memberDAO *dao.RestaurantMemberDAO
+	events    *OrderEventHub
+	config    config.DeliveryConfig
+}
+
+func NewDeliveryService(uow *dao.UnitOfWork, driverDAO *dao.DriverDAO, offerDAO *dao.DeliveryOfferDAO, orderDAO *dao.OrderDAO, memberDAO *dao.RestaurantMemberDAO, events *OrderEventHub, cfg config.DeliveryConfig) *DeliveryService {
+	return &DeliveryService{
+		uow:       uow,
+		driverDAO: driverDAO,
+		offerDAO:  offerDAO,
+		orderDAO:  orderDAO,
+		memberDAO: memberDAO,
+		events:    events,
+		config:    cfg.WithDefaults(),
+	}
+}
+
+// GetProfile returns the driver profile of the actor
+func (s *DeliveryService) GetProfile(actor Actor) (*models.Driver, error) {
+	driver, err := s.driverDAO.GetByUserID(actor.UserID)
+	if err != nil {
+		return nil, errors.New("driver profile not found")
+	}
+	return driver, nil
+}
+
+// UpdateProfile creates or updates the vehicle details of the actor's driver
+// profile. Availability and location are changed through their own calls.
+func (s *DeliveryService) UpdateProfile(driver *models.Driver, actor Actor) error {
+	driver.VehicleType = strings.TrimSpace(driver.VehicleType)
+	driver.LicensePlate = strings.ToUpper(strings.TrimSpace(driver.LicensePlate))
+	if driver.VehicleType == "" {
+		return errors.New("vehicle type is required")
+	}
+
+	driver.ID = 0
+	driver.UserID = actor.UserID
+	driver.IsAvailable = false
+	driver.Latitude = nil
+	driver.Longitude = nil
+	driver.LocationUpdatedAt = nil
+	if existing, err := s.driverDAO.GetByUserID(actor.UserID); err == nil {
+		driver.ID = existing.ID
+		driver.CreatedAt = existing.CreatedAt
+		driver.IsAvailable = existing.IsAvailable
+		driver.Latitude = existing.Latitude
+		driver.Longitude = existing.Longitude
+		driver.LocationUpdatedAt = existing.LocationUpdatedAt
+	}
+	return s.driverDAO.Save(driver)
+}
+
+// SetAvailability starts or ends the actor's shift. Offers already made and
+// deliveries already accepted are not affected.
+func (s *DeliveryService) SetAvailability(available bool, actor Actor) (*models.Driver, error) {
+	if _, err := s.GetProfile(actor); err != nil {
+		return nil, err
+	}
+	if err := s.driverDAO.UpdateAvailability(actor.UserID, available); err != nil {
+		return nil, err
+	}
+	return s.GetProfile(actor)
+}
+
+// UpdateLocation records the actor's current location. While the driver has
+// orders to deliver, the ping is also added to their tracking route.
+func (s *DeliveryService) UpdateLocation(update *models.DriverLocationUpdate, actor Actor) error {
+	if err := validateLocation(update.Latitude, update.Longitude); err != nil {
+		return err
+	}
+	if _, err := s.GetProfile(actor); err != nil {
+		return err
+	}
+
+	return s.uow.Do(func(tx *dao.Tx) error {
+		if err := tx.Drivers.UpdateLocation(actor.UserID, *update.Latitude, *update.Longitude, time.Now()); err != nil {
+			return err
+		}
+		orders, err := tx.Orders.GetActiveByDriverID(actor.UserID)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			err := tx.Drivers.CreateLocation(&models.DriverLocation{
+				DriverID:  actor.UserID,
+				OrderID:   order.ID,
+				Latitude:  *update.Latitude,
+				Longitude: *update.Longitude,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetOffers returns the offers awaiting the actor's answer
+func (s *DeliveryService) GetOffers(actor Actor) ([]models.DeliveryOffer, error) {
+	return s.offerDAO.GetOpenByDriverID(actor.UserID, time.Now())
+}
+
+// AcceptOffer assigns the offered order to the actor
+func (s *DeliveryService) AcceptOffer(offerID uint, actor Actor) (*models.DeliveryOffer, error) {
+	var offer *models.DeliveryOffer
+	var order *models.Order
+	err := s.respond(offerID, actor, func(tx *dao.Tx, o *models.DeliveryOffer) error {
+		var err error
+		offer = o
+		order, err = tx.Orders.GetByIDForUpdate(offer.OrderID)
+		if err != nil {
+			return errors.New("order not found")
+		}
+		if err := tx.Orders.AssignDriver(order.ID, actor.UserID); err != nil {
+			return err
+		}
+		offer.Status = models.DeliveryOfferStatusAccepted
+		if err := tx.Offers.Update(offer); err != nil {
+			return err
+		}
+		return tx.Orders.CreateStatusEvent(newStatusEvent(order.ID, order.Status, order.Status, "assigned to driver", actor))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(models.OrderEvent{
+		Type:         models.OrderEventDriverAssigned,
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		UserID:       order.UserID,
+		DriverID:     &offer.DriverID,
+		Status:       order.Status,
+	})
+	return offer, nil
+}
+
+// DeclineOffer turns down an offer, so the order is offered to another driver
+func (s *DeliveryService) DeclineOffer(offerID uint, actor Actor) (*models.DeliveryOffer, error) {
+	var offer *models.DeliveryOffer
+	err := s.respond(offerID, actor, func(tx *dao.Tx, o *models.DeliveryOffer) error {
+		offer = o
+		offer.Status = models.DeliveryOfferStatusDeclined
+		return tx.Offers.Update(offer)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// respond runs fn with the actor's open offer locked
+func (s *DeliveryService) respond(offerID uint, actor Actor, fn func(tx *dao.Tx, offer *models.DeliveryOffer) error) error {
+	return s.uow.Do(func(tx *dao.Tx) error {
+		offer, err := tx.Offers.GetByIDForUpdate(offerID)
+		if err != nil || offer.DriverID != actor.UserID {
+			return errors.New("offer not found")
+		}
+		now := time.Now()
+		if offer.Status != models.DeliveryOfferStatusOffered {
+			return errors.New("offer has already been answered")
+		}
+		if !now.Before(offer.ExpiresAt) {
+			return errors.New("offer has expired")
+		}
+		offer.RespondedAt = &now
+		return fn(tx, offer)
+	})
+}
+
+// GetDeliveries returns the orders the actor is delivering
+func (s *DeliveryService) GetDeliveries(actor Actor) ([]models.Order, error) {
+	return s.orderDAO.GetActiveByDriverID(actor.UserID)
+}
+
+// GetTracking returns the driver and route of an order to its customer, the
+// restaurant's members, its driver and admins
+func (s *DeliveryService) GetTracking(orderID uint, actor Actor) (*models.DeliveryTracking, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if !isOrderDriver(order, actor) && order.UserID != actor.UserID && checkRestaurantMember(s.memberDAO, actor, order.RestaurantID) != nil {
+		return nil, ErrForbidden
+	}
+
+	tracking := &models.DeliveryTracking{
+		OrderID:           order.ID,
+		Status:            order.Status,
+		DriverID:          order.DriverID,
+		DeliveryLatitude:  order.DeliveryLatitude,
+		DeliveryLongitude: order.DeliveryLongitude,
+		Route:             []models.DriverLocation{},
+	}
+	if order.DriverID == nil {
+		return tracking, nil
+	}
+	if driver, err := s.driverDAO.GetByUserID(*order.DriverID); err == nil {
+		tracking.DriverName = driver.User.Name
+		tracking.DriverPhone = driver.User.Phone
+		tracking.VehicleType = driver.VehicleType
+		tracking.LicensePlate = driver.LicensePlate
+	}
+	if tracking.Route, err = s.driverDAO.GetLocationsByOrderID(order.ID); err != nil {
+		return nil, err
+	}
+	if len(tracking.Route) > 0 {
+		tracking.Location = &tracking.Route[len(tracking.Route)-1]
+	}
+	return tracking, nil
+}
+
+// DispatchOrders expires offers that were not answered in time and offers each
+// ready order without a driver to the nearest available driver. It returns the
+// number of offers made.
+func (s *DeliveryService) DispatchOrders(now time.Time) (int, error) {
+	if _, err := s.offerDAO.ExpireBefore(now); err != nil {
+		return 0, err
+	}
+	orders, err := s.orderDAO.GetAwaitingDriver(dispatchBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	seenSince := now.Add(-time.Duration(s.config.LocationMaxAgeSeconds) * time.Second)
+	offered := 0
+	for _, order := range orders {
+		// Drivers are found around the restaurant, so it needs a location
+		if order.Restaurant.Latitude == nil || order.Restaurant.Longitude == nil {
+			continue
+		}
+		drivers, err := s.driverDAO.GetAvailableNear(*order.Restaurant.Latitude, *order.Restaurant.Longitude,
+			s.config.OfferRadiusKm, seenSince, order.ID, 1)
+		if err != nil {
+			return offered, err
+		}
+		if len(drivers) == 0 {
+			continue
+		}
+
+		offer := &models.DeliveryOffer{
+			OrderID:   order.ID,
+			DriverID:  drivers[0].UserID,
+			Status:    models.DeliveryOfferStatusOffered,
+			ExpiresAt: now.Add(time.Duration(s.config.OfferTimeoutSeconds) * time.Second),
+		}
+		if drivers[0].DistanceKm != nil {
+			offer.DistanceKm = *drivers[0].DistanceKm
+		}
+		if err := s.offerDAO.Create(offer); err != nil {
+			// The order or driver received an offer elsewhere in the meantime
+			log.Printf("Failed to offer order %d to driver %d: %v", order.ID, offer.DriverID, err)
+			continue
+		}
+		offered++
+	}
+	return offered, nil
+}
+
+// DeliveryDispatcher periodically offers ready orders to drivers
+type DeliveryDispatcher struct {
+	deliveryService *DeliveryService
+	interval        time.Duration
+	stop            chan struct{}
+}
+
+func NewDeliveryDispatcher(deliveryService *DeliveryService) *DeliveryDispatcher {
+	return &DeliveryDispatcher{
+		deliveryService: deliveryService,
+		interval:        time.Duration(deliveryService.config.PollSeconds) * time.Second,
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start runs the dispatcher in the background until Stop is called
+func (d *DeliveryDispatcher) Start() {
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			d.run()
+			select {
+			case <-ticker.C:
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background dispatcher
+func (d *DeliveryDispatcher) Stop() {
+	close(d.stop)
+}
+
+func (d *DeliveryDispatcher) run() {
+	offered, err := d.deliveryService.DispatchOrders(time.Now())
+	if err != nil {
+		log.Printf("Failed to dispatch ready orders: %v", err)
+		return
+	}
+	if offered > 0 {
+		log.Printf("Offered %d orders to drivers", offered)
+	}
+}
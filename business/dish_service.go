@@ -1,34 +1,203 @@
 package business
 
 import (
+	"errors"
 	"tumdum_backend/dao"
 	"tumdum_backend/models"
 )
 
 type DishService struct {
-	dishDAO *dao.DishDAO
+	dishDAO       *dao.DishDAO
+	restaurantDAO *dao.RestaurantDAO
+	memberDAO     *dao.RestaurantMemberDAO
+	modifierDAO   *dao.ModifierDAO
+	menuDAO       *dao.MenuDAO
 }
 
-func NewDishService(dishDAO *dao.DishDAO) *DishService {
-	return &DishService{dishDAO: dishDAO}
+func NewDishService(dishDAO *dao.DishDAO, restaurantDAO *dao.RestaurantDAO, memberDAO *dao.RestaurantMemberDAO, modifierDAO *dao.ModifierDAO, menuDAO *dao.MenuDAO) *DishService {
+	return &DishService{
+		dishDAO:       dishDAO,
+		restaurantDAO: restaurantDAO,
+		memberDAO:     memberDAO,
+		modifierDAO:   modifierDAO,
+		menuDAO:       menuDAO,
+	}
 }
 
-func (s *DishService) CreateDish(dish *models.Dish) error {
+// AuthorizeRestaurant verifies that the actor may manage the dishes of the restaurant
+func (s *DishService) AuthorizeRestaurant(restaurantID uint, actor Actor) error {
+	return checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager)
+}
+
+func (s *DishService) CreateDish(dish *models.Dish, actor Actor) error {
+	if err := s.AuthorizeRestaurant(dish.RestaurantID, actor); err != nil {
+		return err
+	}
+	if err := s.validatePrice(dish); err != nil {
+		return err
+	}
+	if dish.PrepTimeMinutes < 0 {
+		return errors.New("prep time cannot be negative")
+	}
+	if err := s.validateMenuCategory(dish); err != nil {
+		return err
+	}
+	dish.ModifierGroups = nil
+	// The rating is derived from reviews
+	dish.Rating = 0
+	dish.RatingCount = 0
 	return s.dishDAO.Create(dish)
 }
 
+// RestaurantCurrency returns the currency of the restaurant, which dishes are
+// priced in unless another one is given
+func (s *DishService) RestaurantCurrency(restaurantID uint) (string, error) {
+	restaurant, err := s.restaurantDAO.GetByID(restaurantID)
+	if err != nil {
+		return "", errors.New("restaurant not found")
+	}
+	return restaurant.Currency, nil
+}
+
+// validatePrice checks that the dish is priced in its restaurant's currency
+func (s *DishService) validatePrice(dish *models.Dish) error {
+	if !dish.Price.IsPositive() {
+		return errors.New("dish price must be greater than zero")
+	}
+	restaurant, err := s.restaurantDAO.GetByID(dish.RestaurantID)
+	if err != nil {
+		return errors.New("restaurant not found")
+	}
+	if dish.Price.Currency != restaurant.Currency {
+		return errors.New("dish price currency must match the restaurant currency")
+	}
+	return nil
+}
+
+// validateMenuCategory checks that the dish's menu category belongs to its
+// restaurant and keeps the free-text category in line with it
+func (s *DishService) validateMenuCategory(dish *models.Dish) error {
+	if dish.MenuCategoryID == nil {
+		return nil
+	}
+	category, err := s.menuDAO.GetCategoryByID(*dish.MenuCategoryID)
+	if err != nil || category.RestaurantID != dish.RestaurantID {
+		return errors.New("menu category not found")
+	}
+	dish.Category = category.Name
+	return nil
+}
+
 func (s *DishService) GetDishByID(id uint) (*models.Dish, error) {
 	return s.dishDAO.GetByID(id)
 }
 
-func (s *DishService) GetDishesByRestaurantID(restaurantID uint) ([]models.Dish, error) {
-	return s.dishDAO.GetByRestaurantID(restaurantID)
+func (s *DishService) GetDishesByRestaurantID(restaurantID uint, filter models.DishFilter, page models.PageRequest) (*models.Page[models.Dish], error) {
+	return s.dishDAO.GetByRestaurantID(restaurantID, filter, page)
 }
 
-func (s *DishService) UpdateDish(dish *models.Dish) error {
+func (s *DishService) UpdateDish(dish *models.Dish, actor Actor) error {
+	if err := s.AuthorizeRestaurant(dish.RestaurantID, actor); err != nil {
+		return err
+	}
+	if err := s.validatePrice(dish); err != nil {
+		return err
+	}
+	if dish.PrepTimeMinutes < 0 {
+		return errors.New("prep time cannot be negative")
+	}
+	if err := s.validateMenuCategory(dish); err != nil {
+		return err
+	}
+	// Modifier groups are managed through their own endpoints
+	dish.ModifierGroups = nil
 	return s.dishDAO.Update(dish)
 }
 
-func (s *DishService) DeleteDish(id uint) error {
+func (s *DishService) DeleteDish(id uint, actor Actor) error {
+	dish, err := s.dishDAO.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.AuthorizeRestaurant(dish.RestaurantID, actor); err != nil {
+		return err
+	}
 	return s.dishDAO.Delete(id)
 }
+
+// GetModifierGroups returns the modifier groups of a dish with their options
+func (s *DishService) GetModifierGroups(dishID uint) ([]models.ModifierGroup, error) {
+	return s.modifierDAO.GetGroupsByDishIDs(dishID)
+}
+
+func (s *DishService) CreateModifierGroup(dishID uint, group *models.ModifierGroup, actor Actor) error {
+	dish, err := s.dishDAO.GetByID(dishID)
+	if err != nil {
+		return errors.New("dish not found")
+	}
+	if err := s.AuthorizeRestaurant(dish.RestaurantID, actor); err != nil {
+		return err
+	}
+
+	group.ID = 0
+	group.DishID = dish.ID
+	for i := range group.Options {
+		group.Options[i].ID = 0
+	}
+	if err := validateModifierGroup(group, dish.Restaurant.Currency); err != nil {
+		return err
+	}
+	return s.modifierDAO.CreateGroup(group)
+}
+
+// UpdateModifierGroup replaces the settings and options of a modifier group.
+// Options keep their ID when it is sent back, others are added or removed.
+func (s *DishService) UpdateModifierGroup(dishID uint, group *models.ModifierGroup, actor Actor) error {
+	dish, existing, err := s.getModifierGroup(dishID, group.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.AuthorizeRestaurant(dish.RestaurantID, actor); err != nil {
+		return err
+	}
+
+	optionIDs := make(map[uint]bool, len(existing.Options))
+	for _, option := range existing.Options {
+		optionIDs[option.ID] = true
+	}
+	for _, option := range group.Options {
+		if option.ID != 0 && !optionIDs[option.ID] {
+			return errors.New("modifier option does not belong to the group")
+		}
+	}
+
+	group.DishID = dish.ID
+	group.CreatedAt = existing.CreatedAt
+	if err := validateModifierGroup(group, dish.Restaurant.Currency); err != nil {
+		return err
+	}
+	return s.modifierDAO.UpdateGroup(group)
+}
+
+func (s *DishService) DeleteModifierGroup(dishID, groupID uint, actor Actor) error {
+	dish, _, err := s.getModifierGroup(dishID, groupID)
+	if err != nil {
+		return err
+	}
+	if err := s.AuthorizeRestaurant(dish.RestaurantID, actor); err != nil {
+		return err
+	}
+	return s.modifierDAO.DeleteGroup(groupID)
+}
+
+func (s *DishService) getModifierGroup(dishID, groupID uint) (*models.Dish, *models.ModifierGroup, error) {
+	dish, err := s.dishDAO.GetByID(dishID)
+	if err != nil {
+		return nil, nil, errors.New("dish not found")
+	}
+	group, err := s.modifierDAO.GetGroupByID(groupID)
+	if err != nil || group.DishID != dish.ID {
+		return nil, nil, errors.New("modifier group not found")
+	}
+	return dish, group, nil
+}
@@ -0,0 +1,78 @@
+package business
+
+import (
+	"math"
+	"time"
+	"tumdum_backend/config"
+	"tumdum_backend/models"
+)
+
+// kitchenQueueStatuses are the statuses of orders the kitchen is working on,
+// which delay newly confirmed orders
+var kitchenQueueStatuses = []models.OrderStatus{models.OrderStatusConfirmed, models.OrderStatusPreparing}
+
+// OrderEstimator predicts when orders will be ready and delivered. The kitchen
+// prepares an order's dishes side by side, so the slowest dish sets its prep
+// time, and works through the orders ahead of it first. Delivery takes the
+// handoff to a driver and the trip at an average speed.
+type OrderEstimator struct {
+	config config.EstimatesConfig
+}
+
+func NewOrderEstimator(cfg config.EstimatesConfig) *OrderEstimator {
+	return &OrderEstimator{config: cfg.WithDefaults()}
+}
+
+// PrepTime is how long the kitchen needs for the given dishes
+func (e *OrderEstimator) PrepTime(dishes []models.Dish) time.Duration {
+	minutes := 0
+	for _, dish := range dishes {
+		prep := dish.PrepTimeMinutes
+		if prep <= 0 {
+			prep = e.config.DefaultPrepMinutes
+		}
+		minutes = max(minutes, prep)
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// TravelTime is how long the trip from the restaurant to the order's delivery
+// location takes
+func (e *OrderEstimator) TravelTime(order *models.Order) time.Duration {
+	if order.DeliveryDistanceKm == nil {
+		return time.Duration(e.config.DefaultTravelMinutes) * time.Minute
+	}
+	minutes := math.Ceil(*order.DeliveryDistanceKm / e.config.SpeedKmh * 60)
+	return time.Duration(minutes) * time.Minute
+}
+
+// Estimate sets the order's ready and delivery times for the status it is moving
+// to. prep is the time its dishes take and queued the number of other orders the
+// kitchen is working on. Orders are estimated once confirmed; until then, and
+// once cancelled, their estimates are left as they are.
+func (e *OrderEstimator) Estimate(order *models.Order, status models.OrderStatus, prep time.Duration, queued int64, now time.Time) {
+	handoff := time.Duration(e.config.HandoffMinutes) * time.Minute
+	var readyAt, deliveryAt time.Time
+	switch status {
+	case models.OrderStatusConfirmed:
+		readyAt = now.Add(time.Duration(queued*int64(e.config.QueueMinutesPerOrder))*time.Minute + prep)
+		deliveryAt = readyAt.Add(handoff + e.TravelTime(order))
+	case models.OrderStatusPreparing:
+		readyAt = now.Add(prep)
+		deliveryAt = readyAt.Add(handoff + e.TravelTime(order))
+	case models.OrderStatusReady:
+		readyAt = now
+		deliveryAt = readyAt.Add(handoff + e.TravelTime(order))
+	case models.OrderStatusPickedUp, models.OrderStatusOutForDelivery:
+		deliveryAt = now.Add(e.TravelTime(order))
+	case models.OrderStatusDelivered:
+		deliveryAt = now
+	default:
+		return
+	}
+
+	if !readyAt.IsZero() {
+		order.EstimatedReadyAt = &readyAt
+	}
+	order.EstimatedDeliveryAt = &deliveryAt
+}
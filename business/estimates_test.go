@@ -0,0 +1,87 @@
+package business
+
+import (
+	"testing"
+	"time"
+	"tumdum_backend/config"
+	"tumdum_backend/models"
+)
+
+func TestOrderEstimatorEstimate(t *testing.T) {
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		when := now.Add(time.Duration(minutes) * time.Minute)
+		return &when
+	}
+	distance := 6.0 // 18 minutes at 20 km/h
+
+	tests := []struct {
+		name         string
+		status       models.OrderStatus
+		distanceKm   *float64
+		queued       int64
+		wantReady    *time.Time
+		wantDelivery *time.Time
+	}{
+		// 2 orders ahead take 10 minutes, then 20 minutes of prep, 5 of handoff and 18 of travel
+		{name: "confirmed behind the queue", status: models.OrderStatusConfirmed, distanceKm: &distance, queued: 2, wantReady: at(30), wantDelivery: at(53)},
+		{name: "confirmed without distance", status: models.OrderStatusConfirmed, wantReady: at(20), wantDelivery: at(40)},
+		{name: "preparing", status: models.OrderStatusPreparing, distanceKm: &distance, queued: 2, wantReady: at(20), wantDelivery: at(43)},
+		{name: "ready", status: models.OrderStatusReady, distanceKm: &distance, wantReady: at(0), wantDelivery: at(23)},
+		{name: "picked up", status: models.OrderStatusPickedUp, distanceKm: &distance, wantDelivery: at(18)},
+		{name: "out for delivery", status: models.OrderStatusOutForDelivery, wantDelivery: at(15)},
+		{name: "delivered", status: models.OrderStatusDelivered, wantDelivery: at(0)},
+		{name: "pending", status: models.OrderStatusPending},
+		{name: "cancelled", status: models.OrderStatusCancelled},
+	}
+	estimator := NewOrderEstimator(config.EstimatesConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{DeliveryDistanceKm: tt.distanceKm}
+			estimator.Estimate(order, tt.status, 20*time.Minute, tt.queued, now)
+			if !sameTime(order.EstimatedReadyAt, tt.wantReady) {
+				t.Errorf("ready at = %v, want %v", order.EstimatedReadyAt, tt.wantReady)
+			}
+			if !sameTime(order.EstimatedDeliveryAt, tt.wantDelivery) {
+				t.Errorf("delivery at = %v, want %v", order.EstimatedDeliveryAt, tt.wantDelivery)
+			}
+		})
+	}
+}
+
+func TestOrderEstimatorKeepsEarlierReadyTime(t *testing.T) {
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.UTC)
+	readyAt := now.Add(-5 * time.Minute)
+	order := &models.Order{EstimatedReadyAt: &readyAt}
+	NewOrderEstimator(config.EstimatesConfig{}).Estimate(order, models.OrderStatusPickedUp, 0, 0, now)
+	if !sameTime(order.EstimatedReadyAt, &readyAt) {
+		t.Errorf("ready at = %v, want %v", order.EstimatedReadyAt, readyAt)
+	}
+}
+
+func TestOrderEstimatorPrepTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		dishes []models.Dish
+		want   time.Duration
+	}{
+		{name: "no dishes", want: 0},
+		{name: "slowest dish", dishes: []models.Dish{{PrepTimeMinutes: 12}, {PrepTimeMinutes: 25}, {PrepTimeMinutes: 8}}, want: 25 * time.Minute},
+		{name: "default for dishes without a prep time", dishes: []models.Dish{{PrepTimeMinutes: 0}, {PrepTimeMinutes: 4}}, want: 10 * time.Minute},
+	}
+	estimator := NewOrderEstimator(config.EstimatesConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimator.PrepTime(tt.dishes); got != tt.want {
+				t.Errorf("PrepTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func sameTime(got, want *time.Time) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return got.Equal(*want)
+}
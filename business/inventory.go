@@ -0,0 +1,196 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// stockResetInterval is how often dishes are checked for the start of a new day
+const stockResetInterval = time.Minute
+
+// reserveStock takes the ordered quantities from the daily stock of the dishes
+// that track stock. In strict mode an order larger than the remaining stock is
+// rejected, otherwise the stock only drops to zero. The dish rows are locked until
+// the transaction ends.
+func reserveStock(tx *dao.Tx, items []models.OrderItem, strict bool) error {
+	quantities := orderedQuantities(items)
+	if len(quantities) == 0 {
+		return nil
+	}
+	dishes, err := tx.Dishes.GetByIDsForUpdate(mapKeys(quantities))
+	if err != nil {
+		return err
+	}
+
+	for _, dish := range dishes {
+		if dish.StockRemaining == nil {
+			continue
+		}
+		quantity := quantities[dish.ID]
+		if strict && *dish.StockRemaining < quantity {
+			if *dish.StockRemaining == 0 {
+				return fmt.Errorf("%q is sold out", dish.Name)
+			}
+			return fmt.Errorf("only %d of %q left", *dish.StockRemaining, dish.Name)
+		}
+		if err := tx.Dishes.AdjustStock(dish.ID, -quantity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseStock returns the items of a cancelled order to the daily stock. Stock
+// that has been reset since the order took it is left alone.
+func releaseStock(tx *dao.Tx, order *models.Order) error {
+	if order.StockReservedAt == nil {
+		return nil
+	}
+	if err := returnStock(tx, order.OrderItems, *order.StockReservedAt); err != nil {
+		return err
+	}
+	order.StockReservedAt = nil
+	return tx.Orders.SetStockReservedAt(order.ID, nil)
+}
+
+// returnStock adds the quantities of items taken at reservedAt back to the daily
+// stock of their dishes, skipping dishes whose stock was reset since
+func returnStock(tx *dao.Tx, items []models.OrderItem, reservedAt time.Time) error {
+	quantities := orderedQuantities(items)
+	if len(quantities) == 0 {
+		return nil
+	}
+	dishes, err := tx.Dishes.GetByIDsForUpdate(mapKeys(quantities))
+	if err != nil {
+		return err
+	}
+	for _, dish := range dishes {
+		if dish.StockRemaining == nil {
+			continue
+		}
+		if dish.StockResetAt != nil && dish.StockResetAt.After(reservedAt) {
+			continue
+		}
+		if err := tx.Dishes.AdjustStock(dish.ID, quantities[dish.ID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderedQuantities sums the ordered quantity per dish, leaving out refunded items
+func orderedQuantities(items []models.OrderItem) map[uint]int {
+	quantities := make(map[uint]int, len(items))
+	for _, item := range items {
+		if quantity := item.RemainingQuantity(); quantity > 0 {
+			quantities[item.DishID] += quantity
+		}
+	}
+	return quantities
+}
+
+func mapKeys(m map[uint]int) []uint {
+	keys := make([]uint, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// UpdateStock sets the daily and remaining stock of a dish. Any member of the
+// restaurant may adjust stock, so kitchens can correct counts during service.
+func (s *DishService) UpdateStock(dishID uint, update *models.DishStockUpdate, actor Actor) (*models.Dish, error) {
+	dish, err := s.dishDAO.GetByID(dishID)
+	if err != nil {
+		return nil, errors.New("dish not found")
+	}
+	if err := checkRestaurantMember(s.memberDAO, actor, dish.RestaurantID); err != nil {
+		return nil, err
+	}
+
+	remaining := update.StockRemaining
+	var resetAt *time.Time
+	isAvailable := dish.IsAvailable
+	if update.DailyStock == nil {
+		// Dishes that sold out are offered again once stock is no longer tracked
+		if dish.StockRemaining != nil && *dish.StockRemaining == 0 {
+			isAvailable = true
+		}
+		remaining = nil
+	} else {
+		if remaining == nil {
+			remaining = update.DailyStock
+		}
+		now := time.Now()
+		resetAt = &now
+		soldOut := dish.StockRemaining != nil && *dish.StockRemaining == 0
+		if *remaining == 0 {
+			isAvailable = false
+		} else if soldOut {
+			isAvailable = true
+		}
+	}
+
+	if err := s.dishDAO.UpdateStock(dish.ID, update.DailyStock, remaining, isAvailable, resetAt); err != nil {
+		return nil, err
+	}
+	return s.dishDAO.GetByID(dish.ID)
+}
+
+// ResetDailyStock refills the stock of dishes at the start of each day in their
+// restaurant's time zone
+func (s *DishService) ResetDailyStock(now time.Time) (int64, error) {
+	return s.dishDAO.ResetDailyStock(now)
+}
+
+// StockResetJob periodically starts a new stock day for dishes that track stock
+type StockResetJob struct {
+	dishService *DishService
+	interval    time.Duration
+	stop        chan struct{}
+}
+
+func NewStockResetJob(dishService *DishService) *StockResetJob {
+	return &StockResetJob{
+		dishService: dishService,
+		interval:    stockResetInterval,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Start runs the job in the background until Stop is called
+func (j *StockResetJob) Start() {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			j.run()
+			select {
+			case <-ticker.C:
+			case <-j.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background job
+func (j *StockResetJob) Stop() {
+	close(j.stop)
+}
+
+func (j *StockResetJob) run() {
+	reset, err := j.dishService.ResetDailyStock(time.Now())
+	if err != nil {
+		log.Printf("Failed to reset daily dish stock: %v", err)
+		return
+	}
+	if reset > 0 {
+		log.Printf("Reset the daily stock of %d dishes", reset)
+	}
+}
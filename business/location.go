@@ -0,0 +1,18 @@
+package business
+
+import (
+	"errors"
+	"tumdum_backend/models"
+)
+
+// validateLocation checks that latitude and longitude are either both unset or
+// both set to a valid position
+func validateLocation(lat, lng *float64) error {
+	if (lat == nil) != (lng == nil) {
+		return errors.New("latitude and longitude must be provided together")
+	}
+	if lat != nil && !models.ValidCoordinates(*lat, *lng) {
+		return errors.New("invalid coordinates")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package business
+
+import (
+	"errors"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// ErrForbidden is returned when the caller is not allowed to perform an operation
+var ErrForbidden = errors.New("insufficient permissions")
+
+// Actor identifies the authenticated user performing an operation
+type Actor struct {
+	UserID uint
+	Role   models.UserRole
+}
+
+// IsAdmin reports whether the actor is a platform administrator
+func (a Actor) IsAdmin() bool {
+	return a.Role == models.UserRoleAdmin
+}
+
+// checkRestaurantMember verifies that the actor is a member of the restaurant holding
+// one of the given roles. Admins are always allowed. With no roles, any member is allowed.
+func checkRestaurantMember(memberDAO *dao.RestaurantMemberDAO, actor Actor, restaurantID uint, roles ...models.MemberRole) error {
+	if actor.IsAdmin() {
+		return nil
+	}
+
+	member, err := memberDAO.GetByRestaurantAndUser(restaurantID, actor.UserID)
+	if err != nil || member == nil {
+		return ErrForbidden
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	for _, role := range roles {
+		if member.Role == role {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,209 @@
+package business
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+type MenuService struct {
+	menuDAO       *dao.MenuDAO
+	restaurantDAO *dao.RestaurantDAO
+	memberDAO     *dao.RestaurantMemberDAO
+}
+
+func NewMenuService(menuDAO *dao.MenuDAO, restaurantDAO *dao.RestaurantDAO, memberDAO *dao.RestaurantMemberDAO) *MenuService {
+	return &MenuService{
+		menuDAO:       menuDAO,
+		restaurantDAO: restaurantDAO,
+		memberDAO:     memberDAO,
+	}
+}
+
+// GetMenu returns the restaurant's available dishes grouped by the categories that
+// are offered right now. Dishes of inactive or currently unavailable categories are
+// left out, and so are categories without any available dish.
+func (s *MenuService) GetMenu(restaurantID uint) (*models.Menu, error) {
+	restaurant, err := s.restaurantDAO.GetByID(restaurantID)
+	if err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	categories, err := s.menuDAO.GetCategoriesByRestaurantID(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	dishes, err := s.menuDAO.GetAvailableDishes(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	loc := restaurantLocation(restaurant)
+	categoryIndex := make(map[uint]int, len(categories))
+	for i := range categories {
+		categories[i].IsAvailableNow = menuCategoryAvailableAt(&categories[i], loc, now)
+		categoryIndex[categories[i].ID] = i
+	}
+
+	menu := &models.Menu{
+		RestaurantID: restaurant.ID,
+		TimeZone:     restaurant.TimeZone,
+		Categories:   []models.MenuCategory{},
+		OtherDishes:  []models.Dish{},
+	}
+	for _, dish := range dishes {
+		if dish.MenuCategoryID == nil {
+			menu.OtherDishes = append(menu.OtherDishes, dish)
+			continue
+		}
+		if i, ok := categoryIndex[*dish.MenuCategoryID]; ok && categories[i].IsAvailableNow {
+			categories[i].Dishes = append(categories[i].Dishes, dish)
+		}
+	}
+	for _, category := range categories {
+		if len(category.Dishes) > 0 {
+			menu.Categories = append(menu.Categories, category)
+		}
+	}
+	return menu, nil
+}
+
+// GetCategories returns all menu categories of a restaurant in display order,
+// including inactive ones and ones outside their availability window
+func (s *MenuService) GetCategories(restaurantID uint) ([]models.MenuCategory, error) {
+	restaurant, err := s.restaurantDAO.GetByID(restaurantID)
+	if err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	categories, err := s.menuDAO.GetCategoriesByRestaurantID(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	loc := restaurantLocation(restaurant)
+	for i := range categories {
+		categories[i].IsAvailableNow = menuCategoryAvailableAt(&categories[i], loc, now)
+	}
+	return categories, nil
+}
+
+func (s *MenuService) CreateCategory(restaurantID uint, category *models.MenuCategory, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return err
+	}
+	restaurant, err := s.restaurantDAO.GetByID(restaurantID)
+	if err != nil {
+		return errors.New("restaurant not found")
+	}
+	if err := validateMenuCategory(category); err != nil {
+		return err
+	}
+
+	category.ID = 0
+	category.RestaurantID = restaurantID
+	category.Dishes = nil
+	if err := s.menuDAO.CreateCategory(category); err != nil {
+		return err
+	}
+	category.IsAvailableNow = menuCategoryAvailableAt(category, restaurantLocation(restaurant), time.Now())
+	return nil
+}
+
+func (s *MenuService) UpdateCategory(restaurantID uint, category *models.MenuCategory, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return err
+	}
+	restaurant, err := s.restaurantDAO.GetByID(restaurantID)
+	if err != nil {
+		return errors.New("restaurant not found")
+	}
+	existing, err := s.menuDAO.GetCategoryByID(category.ID)
+	if err != nil || existing.RestaurantID != restaurantID {
+		return errors.New("menu category not found")
+	}
+	if err := validateMenuCategory(category); err != nil {
+		return err
+	}
+
+	category.RestaurantID = restaurantID
+	category.CreatedAt = existing.CreatedAt
+	category.Dishes = nil
+	if err := s.menuDAO.UpdateCategory(category); err != nil {
+		return err
+	}
+	category.IsAvailableNow = menuCategoryAvailableAt(category, restaurantLocation(restaurant), time.Now())
+	return nil
+}
+
+func (s *MenuService) DeleteCategory(restaurantID, categoryID uint, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return err
+	}
+	category, err := s.menuDAO.GetCategoryByID(categoryID)
+	if err != nil || category.RestaurantID != restaurantID {
+		return errors.New("menu category not found")
+	}
+	return s.menuDAO.DeleteCategory(categoryID)
+}
+
+// validateMenuCategory checks the name and availability window of a category and
+// rewrites the window times as HH:MM:SS
+func validateMenuCategory(category *models.MenuCategory) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return errors.New("menu category name is required")
+	}
+	if (category.AvailableFrom == nil) != (category.AvailableUntil == nil) {
+		return errors.New("available_from and available_until must be set together")
+	}
+	if category.AvailableFrom == nil {
+		return nil
+	}
+
+	from, err := models.ParseClock(*category.AvailableFrom)
+	if err != nil {
+		return err
+	}
+	until, err := models.ParseClock(*category.AvailableUntil)
+	if err != nil {
+		return err
+	}
+	if from == until {
+		return errors.New("availability window cannot be empty")
+	}
+	fromClock, untilClock := models.FormatClock(from), models.FormatClock(until)
+	category.AvailableFrom, category.AvailableUntil = &fromClock, &untilClock
+	return nil
+}
+
+// menuCategoryAvailableAt reports whether an active category is offered at t in
+// the restaurant's time zone. Categories without a window are always offered.
+func menuCategoryAvailableAt(category *models.MenuCategory, loc *time.Location, t time.Time) bool {
+	if !category.IsActive {
+		return false
+	}
+	if category.AvailableFrom == nil || category.AvailableUntil == nil {
+		return true
+	}
+	from, err := models.ParseClock(*category.AvailableFrom)
+	if err != nil {
+		return false
+	}
+	until, err := models.ParseClock(*category.AvailableUntil)
+	if err != nil {
+		return false
+	}
+
+	local := t.In(loc)
+	sinceMidnight := time.Duration(local.Hour())*time.Hour +
+		time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	if from < until {
+		return sinceMidnight >= from && sinceMidnight < until
+	}
+	// The window runs past midnight
+	return sinceMidnight >= from || sinceMidnight < until
+}
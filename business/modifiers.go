@@ -0,0 +1,102 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"tumdum_backend/models"
+)
+
+// validateModifierGroup checks the selection limits and options of a modifier group.
+// Options without a currency are priced in the restaurant's currency.
+func validateModifierGroup(group *models.ModifierGroup, currency string) error {
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return errors.New("modifier group name is required")
+	}
+	if group.MinSelections < 0 || group.MaxSelections < 0 {
+		return errors.New("modifier selection limits cannot be negative")
+	}
+	if group.MaxSelections != 0 && group.MaxSelections < group.MinRequired() {
+		return errors.New("max_selections cannot be less than min_selections")
+	}
+	if len(group.Options) == 0 {
+		return errors.New("modifier group must have at least one option")
+	}
+	if group.MinRequired() > len(group.Options) {
+		return errors.New("min_selections exceeds the number of options")
+	}
+
+	for i := range group.Options {
+		option := &group.Options[i]
+		option.Name = strings.TrimSpace(option.Name)
+		if option.Name == "" {
+			return errors.New("modifier option name is required")
+		}
+		if option.Price.Currency == "" {
+			option.Price.Currency = currency
+		}
+		if option.Price.Currency != currency {
+			return errors.New("modifier option currency must match the restaurant currency")
+		}
+		if option.Price.Amount < 0 {
+			return errors.New("modifier option price cannot be negative")
+		}
+	}
+	return nil
+}
+
+// selectModifiers validates the options chosen for a dish against its modifier
+// groups and returns them as order item modifiers priced from the current options
+func selectModifiers(groups []models.ModifierGroup, optionIDs []uint) ([]models.OrderItemModifier, error) {
+	type choice struct {
+		group  *models.ModifierGroup
+		option *models.ModifierOption
+	}
+	choices := make(map[uint]choice)
+	for i := range groups {
+		for j := range groups[i].Options {
+			choices[groups[i].Options[j].ID] = choice{&groups[i], &groups[i].Options[j]}
+		}
+	}
+
+	selected := make(map[uint]int, len(groups))
+	seen := make(map[uint]bool, len(optionIDs))
+	modifiers := make([]models.OrderItemModifier, 0, len(optionIDs))
+	for _, id := range optionIDs {
+		c, ok := choices[id]
+		if !ok {
+			return nil, errors.New("modifier option not found for the dish")
+		}
+		if seen[id] {
+			return nil, errors.New("modifier option selected more than once")
+		}
+		if !c.option.IsAvailable {
+			return nil, fmt.Errorf("modifier option %q is not available", c.option.Name)
+		}
+		seen[id] = true
+		selected[c.group.ID]++
+
+		optionID := c.option.ID
+		modifiers = append(modifiers, models.OrderItemModifier{
+			ModifierOptionID: &optionID,
+			GroupName:        c.group.Name,
+			Name:             c.option.Name,
+			Price:            c.option.Price,
+		})
+	}
+
+	for _, group := range groups {
+		count := selected[group.ID]
+		if count == 0 && group.IsRequired {
+			return nil, fmt.Errorf("a selection for %q is required", group.Name)
+		}
+		if count > 0 && count < group.MinRequired() {
+			return nil, fmt.Errorf("select at least %d options for %q", group.MinRequired(), group.Name)
+		}
+		if group.MaxSelections > 0 && count > group.MaxSelections {
+			return nil, fmt.Errorf("select at most %d options for %q", group.MaxSelections, group.Name)
+		}
+	}
+	return modifiers, nil
+}
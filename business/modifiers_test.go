@@ -0,0 +1,84 @@
+package business
+
+import (
+	"testing"
+	"tumdum_backend/models"
+)
+
+// modifierTestGroups are a required size choice and up to two optional toppings
+func modifierTestGroups() []models.ModifierGroup {
+	return []models.ModifierGroup{
+		{ID: 1, Name: "Size", IsRequired: true, MaxSelections: 1, Options: []models.ModifierOption{
+			{ID: 11, Name: "Regular", Price: models.NewMoney(0, "USD"), IsAvailable: true},
+			{ID: 12, Name: "Large", Price: models.NewMoney(300, "USD"), IsAvailable: true},
+		}},
+		{ID: 2, Name: "Toppings", MinSelections: 2, MaxSelections: 3, Options: []models.ModifierOption{
+			{ID: 21, Name: "Olives", Price: models.NewMoney(50, "USD"), IsAvailable: true},
+			{ID: 22, Name: "Mushrooms", Price: models.NewMoney(75, "USD"), IsAvailable: true},
+			{ID: 23, Name: "Anchovies", Price: models.NewMoney(100, "USD"), IsAvailable: false},
+			{ID: 24, Name: "Basil", Price: models.NewMoney(25, "USD"), IsAvailable: true},
+			{ID: 25, Name: "Garlic", Price: models.NewMoney(25, "USD"), IsAvailable: true},
+		}},
+	}
+}
+
+func TestSelectModifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		optionIDs []uint
+		wantNames []string
+		wantErr   string
+	}{
+		{name: "required only", optionIDs: []uint{12}, wantNames: []string{"Large"}},
+		{name: "with toppings", optionIDs: []uint{11, 21, 22}, wantNames: []string{"Regular", "Olives", "Mushrooms"}},
+		{name: "nothing selected", wantErr: `a selection for "Size" is required`},
+		{name: "too many sizes", optionIDs: []uint{11, 12}, wantErr: `select at most 1 options for "Size"`},
+		{name: "too few toppings", optionIDs: []uint{11, 21}, wantErr: `select at least 2 options for "Toppings"`},
+		{name: "too many toppings", optionIDs: []uint{11, 21, 22, 24, 25}, wantErr: `select at most 3 options for "Toppings"`},
+		{name: "unavailable option", optionIDs: []uint{11, 21, 23}, wantErr: `modifier option "Anchovies" is not available`},
+		{name: "duplicate option", optionIDs: []uint{11, 21, 21}, wantErr: "modifier option selected more than once"},
+		{name: "option of another dish", optionIDs: []uint{11, 99}, wantErr: "modifier option not found for the dish"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modifiers, err := selectModifiers(modifierTestGroups(), tt.optionIDs)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(modifiers) != len(tt.wantNames) {
+				t.Fatalf("got %d modifiers, want %d", len(modifiers), len(tt.wantNames))
+			}
+			for i, modifier := range modifiers {
+				if modifier.Name != tt.wantNames[i] {
+					t.Errorf("modifier %d = %q, want %q", i, modifier.Name, tt.wantNames[i])
+				}
+				if modifier.ModifierOptionID == nil || *modifier.ModifierOptionID != tt.optionIDs[i] {
+					t.Errorf("modifier %d has option ID %v, want %d", i, modifier.ModifierOptionID, tt.optionIDs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSelectModifiersCopiesGroupAndPrice(t *testing.T) {
+	modifiers, err := selectModifiers(modifierTestGroups(), []uint{12})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if modifiers[0].GroupName != "Size" || modifiers[0].Price != models.NewMoney(300, "USD") {
+		t.Errorf("modifier = %+v", modifiers[0])
+	}
+}
+
+func TestSelectModifiersNoGroups(t *testing.T) {
+	modifiers, err := selectModifiers(nil, nil)
+	if err != nil || len(modifiers) != 0 {
+		t.Errorf("selectModifiers(nil, nil) = %v, %v", modifiers, err)
+	}
+}
@@ -0,0 +1,105 @@
+package business
+
+import (
+	"sync"
+	"time"
+	"tumdum_backend/models"
+)
+
+// orderEventBuffer is how many events a subscriber may fall behind before it is dropped
+const orderEventBuffer = 32
+
+// OrderEventHub fans order changes out to live subscribers in this process.
+// Events are published once the change is committed. A subscriber that does not
+// keep up is closed rather than slowing down the publisher, so clients should
+// reconnect and reload the order when their stream ends.
+type OrderEventHub struct {
+	mu          sync.Mutex
+	subscribers map[*OrderSubscription]struct{}
+}
+
+func NewOrderEventHub() *OrderEventHub {
+	return &OrderEventHub{subscribers: make(map[*OrderSubscription]struct{})}
+}
+
+// OrderSubscription receives the events matching its filter until it is closed
+type OrderSubscription struct {
+	hub    *OrderEventHub
+	match  func(*models.OrderEvent) bool
+	events chan models.OrderEvent
+}
+
+// Subscribe registers a subscriber for the events match accepts
+func (h *OrderEventHub) Subscribe(match func(*models.OrderEvent) bool) *OrderSubscription {
+	subscription := &OrderSubscription{
+		hub:    h,
+		match:  match,
+		events: make(chan models.OrderEvent, orderEventBuffer),
+	}
+	h.mu.Lock()
+	h.subscribers[subscription] = struct{}{}
+	h.mu.Unlock()
+	return subscription
+}
+
+// Publish sends the event to every matching subscriber without blocking
+func (h *OrderEventHub) Publish(event models.OrderEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for subscription := range h.subscribers {
+		if !subscription.match(&event) {
+			continue
+		}
+		select {
+		case subscription.events <- event:
+		default:
+			delete(h.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// publishCreated announces a new order
+func (h *OrderEventHub) publishCreated(order *models.Order) {
+	h.Publish(models.OrderEvent{
+		Type:         models.OrderEventCreated,
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		UserID:       order.UserID,
+		Status:       order.Status,
+		Order:        order,
+	})
+}
+
+// publishStatusChange announces that an order moved from one status to another
+func (h *OrderEventHub) publishStatusChange(order *models.Order, from, to models.OrderStatus, reason string) {
+	h.Publish(models.OrderEvent{
+		Type:         models.OrderEventStatusChanged,
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		UserID:       order.UserID,
+		DriverID:     order.DriverID,
+		FromStatus:   from,
+		Status:       to,
+		Reason:       reason,
+	})
+}
+
+// Events returns the channel the subscription's events arrive on. It is closed
+// when the subscription ends.
+func (s *OrderSubscription) Events() <-chan models.OrderEvent {
+	return s.events
+}
+
+// Close ends the subscription
+func (s *OrderSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subscribers[s]; ok {
+		delete(s.hub.subscribers, s)
+		close(s.events)
+	}
+}
@@ -0,0 +1,224 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// releaseBatchSize bounds how many scheduled orders are released per scheduler run
+const releaseBatchSize = 100
+
+// checkScheduledSlot validates that a scheduled order can be delivered in the slot
+// starting at scheduledFor: the slot is aligned, far enough ahead, within the
+// booking window, the restaurant is open and the slot is not fully booked
+func (s *OrderService) checkScheduledSlot(tx *dao.Tx, restaurant *models.Restaurant, scheduledFor *time.Time, now time.Time) error {
+	slot := time.Duration(s.scheduling.SlotMinutes) * time.Minute
+	if !isSlotStart(*scheduledFor, restaurantLocation(restaurant), slot) {
+		return fmt.Errorf("scheduled time must start a %d-minute slot", s.scheduling.SlotMinutes)
+	}
+	if scheduledFor.Before(now.Add(time.Duration(s.scheduling.MinLeadMinutes) * time.Minute)) {
+		return fmt.Errorf("scheduled time must be at least %d minutes from now", s.scheduling.MinLeadMinutes)
+	}
+	if scheduledFor.After(now.AddDate(0, 0, s.scheduling.MaxDaysAhead)) {
+		return fmt.Errorf("scheduled time must be within %d days", s.scheduling.MaxDaysAhead)
+	}
+
+	open, err := restaurantOpenAt(tx.Schedules, restaurant, *scheduledFor)
+	if err != nil {
+		return err
+	}
+	if !open {
+		return errors.New("restaurant is closed at the scheduled time")
+	}
+
+	if restaurant.SlotCapacity > 0 {
+		// Locking the restaurant serializes bookings so a slot cannot be overbooked
+		if _, err := tx.Restaurants.GetByIDForUpdate(restaurant.ID); err != nil {
+			return err
+		}
+		booked, err := tx.Orders.CountScheduled(restaurant.ID, *scheduledFor, scheduledFor.Add(slot))
+		if err != nil {
+			return err
+		}
+		if booked >= int64(restaurant.SlotCapacity) {
+			return errors.New("the selected slot is fully booked")
+		}
+	}
+	return nil
+}
+
+// isSlotStart reports whether t falls exactly on a slot boundary of the restaurant's local day
+func isSlotStart(t time.Time, loc *time.Location, slot time.Duration) bool {
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	return local.Sub(midnight)%slot == 0
+}
+
+// GetDeliverySlots returns the bookable slots of a restaurant on a day, given as
+// YYYY-MM-DD in the restaurant's time zone
+func (s *OrderService) GetDeliverySlots(restaurantID uint, date string) ([]models.DeliverySlot, error) {
+	var slots []models.DeliverySlot
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		restaurant, err := tx.Restaurants.GetByID(restaurantID)
+		if err != nil {
+			return errors.New("restaurant not found")
+		}
+
+		loc := restaurantLocation(restaurant)
+		day, err := time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return errors.New("invalid date, expected YYYY-MM-DD")
+		}
+		nextDay := day.AddDate(0, 0, 1)
+
+		now := time.Now()
+		earliest := now.Add(time.Duration(s.scheduling.MinLeadMinutes) * time.Minute)
+		latest := now.AddDate(0, 0, s.scheduling.MaxDaysAhead)
+		slot := time.Duration(s.scheduling.SlotMinutes) * time.Minute
+
+		hours, err := tx.Schedules.GetOpeningHours(restaurant.ID)
+		if err != nil {
+			return err
+		}
+		closures, err := tx.Schedules.GetClosures(day, restaurant.ID)
+		if err != nil {
+			return err
+		}
+		booked, err := tx.Orders.CountScheduledBySlot(restaurant.ID, day, nextDay)
+		if err != nil {
+			return err
+		}
+
+		slots = []models.DeliverySlot{}
+		// Stepping from local midnight keeps slots aligned across DST changes
+		for start := day; start.Before(nextDay); start = start.Add(slot) {
+			if start.Before(earliest) || start.After(latest) {
+				continue
+			}
+			if !isOpenAt(restaurant, hours, closures, start) {
+				continue
+			}
+
+			available := models.DeliverySlot{StartsAt: start, EndsAt: start.Add(slot)}
+			if restaurant.SlotCapacity > 0 {
+				remaining := restaurant.SlotCapacity - int(booked[start.UTC()])
+				if remaining <= 0 {
+					continue
+				}
+				available.Remaining = &remaining
+			}
+			slots = append(slots, available)
+		}
+		return nil
+	})
+	return slots, err
+}
+
+// ReleaseScheduledOrders moves scheduled orders whose slot is close enough into
+// the restaurant's queue as PENDING and returns how many were released
+func (s *OrderService) ReleaseScheduledOrders(now time.Time) (int, error) {
+	cutoff := now.Add(time.Duration(s.scheduling.ReleaseLeadMinutes) * time.Minute)
+	orders, err := s.orderDAO.GetDueScheduled(cutoff, releaseBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, order := range orders {
+		err := s.uow.Do(func(tx *dao.Tx) error {
+			if err := tx.Orders.UpdateStatus(order.ID, models.OrderStatusScheduled, models.OrderStatusPending); err != nil {
+				return err
+			}
+			// The status change locks the order, so its items are read again in case
+			// they were edited since the batch was loaded
+			current, err := tx.Orders.GetByID(order.ID)
+			if err != nil {
+				return err
+			}
+			if err := reserveScheduledStock(tx, current, now); err != nil {
+				return err
+			}
+			return tx.Orders.CreateStatusEvent(newStatusEvent(order.ID, models.OrderStatusScheduled,
+				models.OrderStatusPending, "released for scheduled delivery", Actor{}))
+		})
+		if err != nil {
+			// The order was cancelled or released elsewhere in the meantime
+			log.Printf("Failed to release scheduled order %d: %v", order.ID, err)
+			continue
+		}
+		s.events.publishStatusChange(&order, models.OrderStatusScheduled, models.OrderStatusPending, "released for scheduled delivery")
+		released++
+	}
+	return released, nil
+}
+
+// reserveScheduledStock takes the stock for a scheduled order moving to PENDING.
+// The order was accepted when it was placed, so it may take the last portions of
+// a dish but is never refused for lack of stock.
+func reserveScheduledStock(tx *dao.Tx, order *models.Order, now time.Time) error {
+	if err := reserveStock(tx, order.OrderItems, false); err != nil {
+		return err
+	}
+	order.StockReservedAt = &now
+	return tx.Orders.SetStockReservedAt(order.ID, &now)
+}
+
+// OrderScheduler periodically releases scheduled orders to their restaurants and
+// retries payment settlements the provider failed
+type OrderScheduler struct {
+	orderService *OrderService
+	interval     time.Duration
+	stop         chan struct{}
+}
+
+func NewOrderScheduler(orderService *OrderService) *OrderScheduler {
+	return &OrderScheduler{
+		orderService: orderService,
+		interval:     time.Duration(orderService.scheduling.PollSeconds) * time.Second,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler in the background until Stop is called
+func (s *OrderScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.run()
+			select {
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the background scheduler
+func (s *OrderScheduler) Stop() {
+	close(s.stop)
+}
+
+func (s *OrderScheduler) run() {
+	released, err := s.orderService.ReleaseScheduledOrders(time.Now())
+	if err != nil {
+		log.Printf("Failed to release scheduled orders: %v", err)
+	} else if released > 0 {
+		log.Printf("Released %d scheduled orders", released)
+	}
+
+	settled, err := s.orderService.payments.RetrySettlements(time.Now())
+	if err != nil {
+		log.Printf("Failed to retry payment settlements: %v", err)
+		return
+	}
+	if settled > 0 {
+		log.Printf("Settled %d payments on retry", settled)
+	}
+}
@@ -2,36 +2,226 @@ package business
 
 import (
 	"errors"
+	"fmt"
+	"time"
+	"tumdum_backend/config"
 	"tumdum_backend/dao"
 	"tumdum_backend/models"
 )
 
 type OrderService struct {
-	orderDAO      *dao.OrderDAO
-	dishDAO       *dao.DishDAO
-	restaurantDAO *dao.RestaurantDAO
+	uow        *dao.UnitOfWork
+	orderDAO   *dao.OrderDAO
+	memberDAO  *dao.RestaurantMemberDAO
+	refundDAO  *dao.RefundDAO
+	scheduling config.SchedulingConfig
+	payments   *PaymentService
+	pricer     *OrderPricer
+	estimator  *OrderEstimator
+	events     *OrderEventHub
 }
 
-func NewOrderService(orderDAO *dao.OrderDAO, dishDAO *dao.DishDAO, restaurantDAO *dao.RestaurantDAO) *OrderService {
+func NewOrderService(uow *dao.UnitOfWork, orderDAO *dao.OrderDAO, memberDAO *dao.RestaurantMemberDAO, refundDAO *dao.RefundDAO, scheduling config.SchedulingConfig, payments *PaymentService, pricer *OrderPricer, estimator *OrderEstimator, events *OrderEventHub) *OrderService {
 	return &OrderService{
-		orderDAO:      orderDAO,
-		dishDAO:       dishDAO,
-		restaurantDAO: restaurantDAO,
+		uow:        uow,
+		orderDAO:   orderDAO,
+		memberDAO:  memberDAO,
+		refundDAO:  refundDAO,
+		scheduling: scheduling.WithDefaults(),
+		payments:   payments,
+		pricer:     pricer,
+		estimator:  estimator,
+		events:     events,
 	}
 }
 
 func (s *OrderService) CreateOrder(order *models.Order) error {
-	// Validate restaurant exists
-	restaurant, err := s.restaurantDAO.GetByID(order.RestaurantID)
-	if err != nil || restaurant == nil {
-		return errors.New("restaurant not found")
+	// Payments are only made through the payment endpoints, and discounts only
+	// come from the promo code
+	order.Payments = nil
+	order.Discounts = nil
+
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		// Validate restaurant exists
+		restaurant, err := tx.Restaurants.GetByID(order.RestaurantID)
+		if err != nil || restaurant == nil {
+			return errors.New("restaurant not found")
+		}
+
+		// Orders without a slot are fulfilled as soon as possible
+		status := models.OrderStatusPending
+		if order.ScheduledFor != nil {
+			if err := s.checkScheduledSlot(tx, restaurant, order.ScheduledFor, time.Now()); err != nil {
+				return err
+			}
+			status = models.OrderStatusScheduled
+		} else {
+			open, err := restaurantOpenAt(tx.Schedules, restaurant, time.Now())
+			if err != nil {
+				return err
+			}
+			if !open {
+				return errors.New("restaurant is closed")
+			}
+		}
+
+		if err := resolveDeliveryLocation(tx, order); err != nil {
+			return err
+		}
+		if err := checkDeliveryRadius(order, restaurant); err != nil {
+			return err
+		}
+
+		// Menu categories are checked against the time the order is prepared for
+		preparedAt := time.Now()
+		if order.ScheduledFor != nil {
+			preparedAt = *order.ScheduledFor
+		}
+		if err := priceOrderItems(tx, order, restaurant, &preparedAt); err != nil {
+			return err
+		}
+		if err := s.pricer.ApplyCharges(order, restaurant); err != nil {
+			return err
+		}
+		promotion, err := applyPromotion(tx, order, time.Now())
+		if err != nil {
+			return err
+		}
+		s.pricer.Total(order)
+		order.Status = status
+
+		// Scheduled orders take their stock when they are released to the kitchen
+		order.StockReservedAt = nil
+		if status == models.OrderStatusPending {
+			if err := reserveStock(tx, order.OrderItems, true); err != nil {
+				return err
+			}
+			now := time.Now()
+			order.StockReservedAt = &now
+		}
+
+		if err := tx.Orders.Create(order); err != nil {
+			return err
+		}
+		return redeemPromotion(tx, promotion, order)
+	})
+	if err != nil {
+		return err
+	}
+	s.events.publishCreated(order)
+	return nil
+}
+
+// resolveDeliveryLocation fills in where the order is delivered to: a saved address
+// of the customer, explicit coordinates, or else the customer's default address or
+// profile location
+func resolveDeliveryLocation(tx *dao.Tx, order *models.Order) error {
+	order.DeliveryDistanceKm = nil
+
+	if order.DeliveryAddressID != nil {
+		address, err := tx.Addresses.GetByID(*order.DeliveryAddressID)
+		if err != nil || address.UserID != order.UserID {
+			return errors.New("delivery address not found")
+		}
+		useDeliveryAddress(order, address)
+		return nil
+	}
+
+	if order.DeliveryLatitude != nil || order.DeliveryLongitude != nil {
+		return validateLocation(order.DeliveryLatitude, order.DeliveryLongitude)
+	}
+
+	if address, err := tx.Addresses.GetDefault(order.UserID); err == nil {
+		useDeliveryAddress(order, address)
+		return nil
+	}
+
+	user, err := tx.Users.GetByID(order.UserID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	order.DeliveryLatitude = user.Latitude
+	order.DeliveryLongitude = user.Longitude
+	if order.DeliveryAddress == "" {
+		order.DeliveryAddress = user.Address
+	}
+	return nil
+}
+
+func useDeliveryAddress(order *models.Order, address *models.UserAddress) {
+	lat, lng := address.Latitude, address.Longitude
+	addressID := address.ID
+	order.DeliveryAddressID = &addressID
+	order.DeliveryAddress = address.Address
+	order.DeliveryLatitude = &lat
+	order.DeliveryLongitude = &lng
+}
+
+// checkDeliveryRadius rejects orders delivered outside the restaurant's delivery
+// radius. Restaurants without coordinates cannot enforce a radius.
+func checkDeliveryRadius(order *models.Order, restaurant *models.Restaurant) error {
+	if restaurant.Latitude == nil || restaurant.Longitude == nil {
+		return nil
+	}
+	if order.DeliveryLatitude == nil || order.DeliveryLongitude == nil {
+		return errors.New("delivery location is required")
+	}
+
+	distance := models.DistanceKm(*restaurant.Latitude, *restaurant.Longitude,
+		*order.DeliveryLatitude, *order.DeliveryLongitude)
+	if distance > restaurant.DeliveryRadiusKm {
+		return errors.New("delivery address is outside the restaurant's delivery radius")
+	}
+	order.DeliveryDistanceKm = &distance
+	return nil
+}
+
+// priceOrderItems validates the order's items and selected modifiers, prices
+// them and sets the order subtotal. Prices come from the dish rows, which stay locked until the transaction ends so the
+// snapshot cannot change before the order is written. With a non-nil at, dishes
+// must also be in a menu category offered at that time.
+func priceOrderItems(tx *dao.Tx, order *models.Order, restaurant *models.Restaurant, at *time.Time) error {
+	if len(order.OrderItems) == 0 {
+		return errors.New("order must contain at least one item")
+	}
+
+	dishIDs := make([]uint, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if item.Quantity <= 0 {
+			return errors.New("item quantity must be positive")
+		}
+		dishIDs = append(dishIDs, item.DishID)
+	}
+
+	dishes, err := tx.Dishes.GetByIDsForUpdate(dishIDs)
+	if err != nil {
+		return err
+	}
+	dishByID := make(map[uint]models.Dish, len(dishes))
+	for _, dish := range dishes {
+		dishByID[dish.ID] = dish
+	}
+
+	if at != nil {
+		if err := checkMenuCategories(tx, dishes, restaurant, *at); err != nil {
+			return err
+		}
 	}
 
-	// Calculate total amount and validate dishes
-	totalAmount := 0.0
+	groups, err := tx.Modifiers.GetGroupsByDishIDs(dishIDs...)
+	if err != nil {
+		return err
+	}
+	groupsByDish := make(map[uint][]models.ModifierGroup)
+	for _, group := range groups {
+		groupsByDish[group.DishID] = append(groupsByDish[group.DishID], group)
+	}
+
+	// Calculate the subtotal and validate dishes
+	subtotal := models.NewMoney(0, restaurant.Currency)
 	for i, item := range order.OrderItems {
-		dish, err := s.dishDAO.GetByID(item.DishID)
-		if err != nil || dish == nil {
+		dish, ok := dishByID[item.DishID]
+		if !ok {
 			return errors.New("dish not found")
 		}
 		if dish.RestaurantID != order.RestaurantID {
@@ -40,44 +230,353 @@ func (s *OrderService) CreateOrder(order *models.Order) error {
 		if !dish.IsAvailable {
 			return errors.New("dish is not available")
 		}
+		if !dish.Price.SameCurrency(subtotal) {
+			return errors.New("dish price currency does not match the restaurant currency")
+		}
+
+		optionIDs := make([]uint, 0, len(item.Modifiers))
+		for _, modifier := range item.Modifiers {
+			if modifier.ModifierOptionID == nil {
+				return errors.New("modifier_option_id is required")
+			}
+			optionIDs = append(optionIDs, *modifier.ModifierOptionID)
+		}
+		modifiers, err := selectModifiers(groupsByDish[dish.ID], optionIDs)
+		if err != nil {
+			return err
+		}
+
+		unitPrice := dish.Price
+		for _, modifier := range modifiers {
+			if !modifier.Price.SameCurrency(unitPrice) {
+				return errors.New("modifier price currency does not match the restaurant currency")
+			}
+			unitPrice = unitPrice.Add(modifier.Price)
+		}
+
 		order.OrderItems[i].Price = dish.Price
-		totalAmount += dish.Price * float64(item.Quantity)
+		order.OrderItems[i].RefundedQuantity = 0
+		order.OrderItems[i].Modifiers = modifiers
+		order.OrderItems[i].LineTotal = unitPrice.Multiply(int64(item.Quantity))
+		subtotal = subtotal.Add(order.OrderItems[i].LineTotal)
 	}
+	order.SubtotalAmount = subtotal
+	return nil
+}
 
-	order.TotalAmount = totalAmount
-	order.Status = models.OrderStatusPending
+// checkMenuCategories rejects dishes whose menu category is inactive or outside
+// its availability window at the given time
+func checkMenuCategories(tx *dao.Tx, dishes []models.Dish, restaurant *models.Restaurant, at time.Time) error {
+	categoryIDs := make([]uint, 0, len(dishes))
+	for _, dish := range dishes {
+		if dish.MenuCategoryID != nil {
+			categoryIDs = append(categoryIDs, *dish.MenuCategoryID)
+		}
+	}
+	if len(categoryIDs) == 0 {
+		return nil
+	}
 
-	return s.orderDAO.Create(order)
+	categories, err := tx.Menus.GetCategoriesByIDs(categoryIDs)
+	if err != nil {
+		return err
+	}
+	loc := restaurantLocation(restaurant)
+	for i := range categories {
+		if !menuCategoryAvailableAt(&categories[i], loc, at) {
+			return fmt.Errorf("dishes from %q are not available at this time", categories[i].Name)
+		}
+	}
+	return nil
 }
 
 func (s *OrderService) GetOrderByID(id uint) (*models.Order, error) {
 	return s.orderDAO.GetByID(id)
 }
 
-func (s *OrderService) GetOrdersByUserID(userID uint) ([]models.Order, error) {
-	return s.orderDAO.GetByUserID(userID)
+func (s *OrderService) GetOrdersByUserID(userID uint, filter models.OrderFilter, page models.PageRequest) (*models.Page[models.Order], error) {
+	filter.UserID = userID
+	return s.orderDAO.GetAll(filter, page)
+}
+
+// GetRestaurantOrders returns a page of a restaurant's orders to its members
+func (s *OrderService) GetRestaurantOrders(restaurantID uint, filter models.OrderFilter, page models.PageRequest, actor Actor) (*models.Page[models.Order], error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID); err != nil {
+		return nil, err
+	}
+	filter.RestaurantID = restaurantID
+	return s.orderDAO.GetAll(filter, page)
+}
+
+// nextKitchenStatus is the status the kitchen moves an order to from each of its statuses
+var nextKitchenStatus = map[models.OrderStatus]models.OrderStatus{
+	models.OrderStatusConfirmed: models.OrderStatusPreparing,
+	models.OrderStatusPreparing: models.OrderStatusReady,
 }
 
-func (s *OrderService) UpdateOrderStatus(orderID uint, status models.OrderStatus) error {
+// GetKitchenQueue returns the confirmed, preparing and ready orders of a
+// restaurant to its members, with how long each has been in its status
+func (s *OrderService) GetKitchenQueue(restaurantID uint, actor Actor) (*models.KitchenQueue, error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID); err != nil {
+		return nil, err
+	}
+	orders, err := s.orderDAO.GetKitchenOrders(restaurantID, models.KitchenStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	queue := &models.KitchenQueue{
+		Confirmed: []models.KitchenOrder{},
+		Preparing: []models.KitchenOrder{},
+		Ready:     []models.KitchenOrder{},
+	}
+	for _, order := range orders {
+		kitchenOrder := models.KitchenOrder{Order: order}
+		if order.StatusChangedAt != nil {
+			kitchenOrder.ElapsedSeconds = int64(now.Sub(*order.StatusChangedAt).Seconds())
+		}
+		switch order.Status {
+		case models.OrderStatusConfirmed:
+			queue.Confirmed = append(queue.Confirmed, kitchenOrder)
+		case models.OrderStatusPreparing:
+			queue.Preparing = append(queue.Preparing, kitchenOrder)
+		case models.OrderStatusReady:
+			queue.Ready = append(queue.Ready, kitchenOrder)
+		}
+	}
+	return queue, nil
+}
+
+// AdvanceKitchenOrders moves each of the restaurant's orders to its next kitchen
+// status: confirmed orders start preparing and preparing orders become ready.
+// Orders are moved one by one, so one that cannot be moved does not hold back
+// the others; the result of each is reported.
+func (s *OrderService) AdvanceKitchenOrders(restaurantID uint, orderIDs []uint, actor Actor) ([]models.KitchenAdvanceResult, error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID); err != nil {
+		return nil, err
+	}
+
+	results := make([]models.KitchenAdvanceResult, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		result := models.KitchenAdvanceResult{OrderID: orderID}
+		order, err := s.orderDAO.GetByID(orderID)
+		if err != nil || order.RestaurantID != restaurantID {
+			result.Error = "order not found"
+			results = append(results, result)
+			continue
+		}
+		next, ok := nextKitchenStatus[order.Status]
+		if !ok {
+			result.Error = "order cannot be advanced from " + string(order.Status)
+		} else if err := s.changeStatus(order, next, "", actor); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Status = next
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CanViewOrder reports whether the actor may see the order: its customer, its
+// driver, any member of its restaurant, or an admin
+func (s *OrderService) CanViewOrder(order *models.Order, actor Actor) bool {
+	if order.UserID == actor.UserID || isOrderDriver(order, actor) {
+		return true
+	}
+	return checkRestaurantMember(s.memberDAO, actor, order.RestaurantID) == nil
+}
+
+func (s *OrderService) UpdateOrderStatus(orderID uint, status models.OrderStatus, reason string, actor Actor) error {
 	order, err := s.orderDAO.GetByID(orderID)
 	if err != nil {
 		return err
 	}
 
+	// Customers may cancel their own orders until the restaurant confirms them and
+	// drivers move the orders they deliver along, every other change is up to the
+	// restaurant
+	customerCancel := order.UserID == actor.UserID && status == models.OrderStatusCancelled &&
+		(order.Status == models.OrderStatusPending || order.Status == models.OrderStatusScheduled)
+	driverUpdate := isOrderDriver(order, actor) && (status == models.OrderStatusPickedUp ||
+		status == models.OrderStatusOutForDelivery || status == models.OrderStatusDelivered)
+	if !customerCancel && !driverUpdate {
+		if err := checkRestaurantMember(s.memberDAO, actor, order.RestaurantID); err != nil {
+			return err
+		}
+	}
+	return s.changeStatus(order, status, reason, actor)
+}
+
+// changeStatus moves an order the actor may update to a new status
+func (s *OrderService) changeStatus(order *models.Order, status models.OrderStatus, reason string, actor Actor) error {
 	// Validate status transition
 	if !isValidStatusTransition(order.Status, status) {
 		return errors.New("invalid status transition")
 	}
 
-	return s.orderDAO.UpdateStatus(orderID, status)
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		// The order is read again under lock, so the items it is estimated for and
+		// the stock it holds are current
+		current, err := lockOrder(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != order.Status {
+			return errors.New("order status was changed concurrently")
+		}
+		*order = *current
+		if order.Status == models.OrderStatusScheduled && status == models.OrderStatusPending {
+			if err := reserveScheduledStock(tx, order, time.Now()); err != nil {
+				return err
+			}
+		}
+		// Restaurants only confirm orders whose payment has been authorized
+		if status == models.OrderStatusConfirmed {
+			if err := requirePaidOrder(tx, order); err != nil {
+				return err
+			}
+		}
+		if err := tx.Orders.UpdateStatus(order.ID, order.Status, status); err != nil {
+			return err
+		}
+		if err := s.estimate(tx, order, status); err != nil {
+			return err
+		}
+		if err := tx.Orders.UpdateEstimates(order); err != nil {
+			return err
+		}
+		if status == models.OrderStatusCancelled {
+			if err := releaseStock(tx, order); err != nil {
+				return err
+			}
+			if err := tx.Promotions.DeleteRedemptionsByOrderID(order.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Orders.CreateStatusEvent(newStatusEvent(order.ID, order.Status, status, reason, actor))
+	})
+	if err != nil {
+		return err
+	}
+	s.payments.SettleOrder(order, status)
+	s.events.publishStatusChange(order, order.Status, status, reason)
+	return nil
+}
+
+// lockOrder locks the order's row and reads it again with its items
+func lockOrder(tx *dao.Tx, orderID uint) (*models.Order, error) {
+	if _, err := tx.Orders.GetByIDForUpdate(orderID); err != nil {
+		return nil, err
+	}
+	return tx.Orders.GetByID(orderID)
+}
+
+// estimate revises the order's ready and delivery times for the status it is moving to
+func (s *OrderService) estimate(tx *dao.Tx, order *models.Order, status models.OrderStatus) error {
+	var dishes []models.Dish
+	var queued int64
+	if status == models.OrderStatusConfirmed || status == models.OrderStatusPreparing {
+		dishIDs := make([]uint, 0, len(order.OrderItems))
+		for _, item := range order.OrderItems {
+			if item.RemainingQuantity() > 0 {
+				dishIDs = append(dishIDs, item.DishID)
+			}
+		}
+		var err error
+		if dishes, err = tx.Dishes.GetByIDs(dishIDs); err != nil {
+			return err
+		}
+	}
+	if status == models.OrderStatusConfirmed {
+		var err error
+		if queued, err = tx.Orders.CountInKitchen(order.RestaurantID, order.ID, kitchenQueueStatuses); err != nil {
+			return err
+		}
+	}
+	s.estimator.Estimate(order, status, s.estimator.PrepTime(dishes), queued, time.Now())
+	return nil
+}
+
+// SubscribeOrder returns the order with a subscription to its changes, for
+// actors who may view it
+func (s *OrderService) SubscribeOrder(orderID uint, actor Actor) (*models.Order, *OrderSubscription, error) {
+	// Subscribing first means no change made while the order loads is missed
+	subscription := s.events.Subscribe(func(event *models.OrderEvent) bool {
+		return event.OrderID == orderID
+	})
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		subscription.Close()
+		return nil, nil, errors.New("order not found")
+	}
+	if !s.CanViewOrder(order, actor) {
+		subscription.Close()
+		return nil, nil, ErrForbidden
+	}
+	return order, subscription, nil
+}
+
+// SubscribeRestaurantOrders returns the pending orders of a restaurant, oldest
+// first, with a subscription to new orders and changes to its orders, for the
+// restaurant's members
+func (s *OrderService) SubscribeRestaurantOrders(restaurantID uint, actor Actor) ([]models.Order, *OrderSubscription, error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID); err != nil {
+		return nil, nil, err
+	}
+	subscription := s.events.Subscribe(func(event *models.OrderEvent) bool {
+		return event.RestaurantID == restaurantID
+	})
+	filter := models.OrderFilter{RestaurantID: restaurantID, Statuses: []models.OrderStatus{models.OrderStatusPending}}
+	orders, err := s.orderDAO.GetAll(filter, models.PageRequest{Limit: models.MaxPageLimit, Sort: "created_at"})
+	if err != nil {
+		subscription.Close()
+		return nil, nil, err
+	}
+	return orders.Data, subscription, nil
+}
+
+// GetOrderTimeline returns the status history of an order, oldest first
+func (s *OrderService) GetOrderTimeline(orderID uint, actor Actor) ([]models.OrderStatusEvent, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.CanViewOrder(order, actor) {
+		return nil, ErrForbidden
+	}
+	return s.orderDAO.GetStatusEvents(orderID)
+}
+
+func newStatusEvent(orderID uint, from, to models.OrderStatus, reason string, actor Actor) *models.OrderStatusEvent {
+	event := &models.OrderStatusEvent{
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+	}
+	if actor.UserID != 0 {
+		actorID := actor.UserID
+		event.ActorID = &actorID
+	}
+	return event
+}
+
+// isOrderDriver reports whether the actor is the driver delivering the order
+func isOrderDriver(order *models.Order, actor Actor) bool {
+	return order.DriverID != nil && *order.DriverID == actor.UserID
 }
 
 func isValidStatusTransition(current, new models.OrderStatus) bool {
 	validTransitions := map[models.OrderStatus][]models.OrderStatus{
-		models.OrderStatusPending:   {models.OrderStatusConfirmed, models.OrderStatusCancelled},
-		models.OrderStatusConfirmed: {models.OrderStatusPreparing},
-		models.OrderStatusPreparing: {models.OrderStatusReady},
-		models.OrderStatusReady:     {models.OrderStatusDelivered},
+		models.OrderStatusScheduled:      {models.OrderStatusPending, models.OrderStatusCancelled},
+		models.OrderStatusPending:        {models.OrderStatusConfirmed, models.OrderStatusCancelled},
+		models.OrderStatusConfirmed:      {models.OrderStatusPreparing},
+		models.OrderStatusPreparing:      {models.OrderStatusReady},
+		models.OrderStatusReady:          {models.OrderStatusPickedUp},
+		models.OrderStatusPickedUp:       {models.OrderStatusOutForDelivery},
+		models.OrderStatusOutForDelivery: {models.OrderStatusDelivered},
 	}
 
 	for _, validStatus := range validTransitions[current] {
@@ -88,50 +587,136 @@ func isValidStatusTransition(current, new models.OrderStatus) bool {
 	return false
 }
 
-func (s *OrderService) GetAllOrders() ([]models.Order, error) {
-	return s.orderDAO.GetAll()
+func (s *OrderService) GetAllOrders(filter models.OrderFilter, page models.PageRequest) (*models.Page[models.Order], error) {
+	return s.orderDAO.GetAll(filter, page)
 }
 
-func (s *OrderService) UpdateOrder(order *models.Order) error {
-	// Validate order exists
-	existingOrder, err := s.orderDAO.GetByID(order.ID)
-	if err != nil {
-		return err
-	}
-
-	// Validate restaurant exists
-	restaurant, err := s.restaurantDAO.GetByID(order.RestaurantID)
-	if err != nil || restaurant == nil {
-		return errors.New("restaurant not found")
-	}
+func (s *OrderService) UpdateOrder(order *models.Order, actor Actor) error {
+	var existingOrder *models.Order
+	var statusChanged bool
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		// The order is read under lock, so the items and stock it holds are current
+		var err error
+		existingOrder, err = lockOrder(tx, order.ID)
+		if err != nil {
+			return err
+		}
 
-	// Calculate total amount and validate dishes
-	totalAmount := 0.0
-	for i, item := range order.OrderItems {
-		dish, err := s.dishDAO.GetByID(item.DishID)
-		if err != nil || dish == nil {
-			return errors.New("dish not found")
+		if err := checkRestaurantMember(s.memberDAO, actor, existingOrder.RestaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+			return err
 		}
-		if dish.RestaurantID != order.RestaurantID {
-			return errors.New("dish does not belong to the restaurant")
+		if order.RestaurantID != existingOrder.RestaurantID {
+			return errors.New("cannot move an order to another restaurant")
 		}
-		if !dish.IsAvailable {
-			return errors.New("dish is not available")
-		}
-		order.OrderItems[i].Price = dish.Price
-		totalAmount += dish.Price * float64(item.Quantity)
-	}
-
-	order.TotalAmount = totalAmount
+		order.UserID = existingOrder.UserID
+		// The delivery location is chosen by the customer when ordering
+		order.DeliveryAddressID = existingOrder.DeliveryAddressID
+		order.DeliveryAddress = existingOrder.DeliveryAddress
+		order.DeliveryLatitude = existingOrder.DeliveryLatitude
+		order.DeliveryLongitude = existingOrder.DeliveryLongitude
+		order.DeliveryDistanceKm = existingOrder.DeliveryDistanceKm
+		order.ScheduledFor = existingOrder.ScheduledFor
+		order.DriverID = existingOrder.DriverID
+		order.StockReservedAt = existingOrder.StockReservedAt
+		order.Payments = nil
+		order.Discounts = existingOrder.Discounts
+		order.TaxRate = existingOrder.TaxRate
+		order.DeliveryFee = existingOrder.DeliveryFee
+		order.PackagingFee = existingOrder.PackagingFee
+		order.TipAmount = existingOrder.TipAmount
+		order.EstimatedReadyAt = existingOrder.EstimatedReadyAt
+		order.EstimatedDeliveryAt = existingOrder.EstimatedDeliveryAt
 
-	// Validate status transition if status is being updated
-	if order.Status != existingOrder.Status {
-		if !isValidStatusTransition(existingOrder.Status, order.Status) {
+		// Validate status transition if status is being updated
+		statusChanged = order.Status != existingOrder.Status
+		if statusChanged && !isValidStatusTransition(existingOrder.Status, order.Status) {
 			return errors.New("invalid status transition")
 		}
-	}
 
-	return s.orderDAO.Update(order)
+		// Once the kitchen has the order or a payment holds its total, a new price
+		// could no longer be charged
+		if existingOrder.Status != models.OrderStatusPending && existingOrder.Status != models.OrderStatusScheduled {
+			return errors.New("only pending or scheduled orders can be edited")
+		}
+		payment, err := tx.Payments.GetActiveByOrderIDForUpdate(order.ID)
+		if err != nil {
+			return err
+		}
+		if payment != nil {
+			return errors.New("orders with an active payment cannot be edited")
+		}
+		// Refund amounts are based on the items as ordered
+		refunds, err := tx.Refunds.CountByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		if refunds > 0 {
+			return errors.New("orders with refunds cannot be edited")
+		}
+		restaurant, err := tx.Restaurants.GetByID(order.RestaurantID)
+		if err != nil {
+			return errors.New("restaurant not found")
+		}
+		// Stock held by the order is returned first, so dishes it sold out can be
+		// priced again, and taken again for the new items unless it is cancelled
+		reserved := existingOrder.StockReservedAt != nil
+		if reserved {
+			if err := releaseStock(tx, existingOrder); err != nil {
+				return err
+			}
+			order.StockReservedAt = nil
+		}
+		if err := priceOrderItems(tx, order, restaurant, nil); err != nil {
+			return err
+		}
+		// Discount lines and charges already on the order are worked out again
+		// for the new subtotal
+		s.pricer.Total(order)
+		if reserved && order.Status != models.OrderStatusCancelled {
+			if err := reserveStock(tx, order.OrderItems, true); err != nil {
+				return err
+			}
+			now := time.Now()
+			order.StockReservedAt = &now
+		} else if existingOrder.Status == models.OrderStatusScheduled && order.Status == models.OrderStatusPending {
+			if err := reserveScheduledStock(tx, order, time.Now()); err != nil {
+				return err
+			}
+		}
+		if statusChanged {
+			if order.Status == models.OrderStatusConfirmed {
+				if err := requirePaidOrder(tx, order); err != nil {
+					return err
+				}
+			}
+			if err := tx.Orders.UpdateStatus(order.ID, existingOrder.Status, order.Status); err != nil {
+				return err
+			}
+			if err := tx.Orders.CreateStatusEvent(newStatusEvent(order.ID, existingOrder.Status, order.Status, "", actor)); err != nil {
+				return err
+			}
+			if err := s.estimate(tx, order, order.Status); err != nil {
+				return err
+			}
+		}
+		if order.Status == models.OrderStatusCancelled && statusChanged {
+			if err := tx.Promotions.DeleteRedemptionsByOrderID(order.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.Orders.UpdateDiscounts(order.Discounts); err != nil {
+			return err
+		}
+		return tx.Orders.Update(order)
+	})
+	if err != nil {
+		return err
+	}
+	if statusChanged {
+		s.payments.SettleOrder(order, order.Status)
+		s.events.publishStatusChange(order, existingOrder.Status, order.Status, "")
+	}
+	return nil
 }
 
 func (s *OrderService) DeleteOrder(id uint) error {
@@ -145,6 +730,23 @@ func (s *OrderService) DeleteOrder(id uint) error {
 	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusCancelled {
 		return errors.New("can only delete pending or cancelled orders")
 	}
+	// Refunds are part of the order's audit trail
+	refunds, err := s.refundDAO.CountByOrderID(id)
+	if err != nil {
+		return err
+	}
+	if refunds > 0 {
+		return errors.New("orders with refunds cannot be deleted")
+	}
 
-	return s.orderDAO.Delete(id)
+	// An authorization held for the order is released before its payments are removed
+	s.payments.SettleOrder(order, models.OrderStatusCancelled)
+
+	return s.uow.Do(func(tx *dao.Tx) error {
+		// A deleted pending order no longer needs its portions
+		if err := releaseStock(tx, order); err != nil {
+			return err
+		}
+		return tx.Orders.Delete(id)
+	})
 }
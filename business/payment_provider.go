@@ -0,0 +1,191 @@
+package business
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"tumdum_backend/models"
+)
+
+// ErrInvalidWebhookSignature is returned when a webhook was not signed by the provider
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// PaymentProvider moves money for orders. Authorize reserves the amount on the
+// customer's payment method, Capture collects up to the authorized amount, Void
+// releases an authorization that will not be captured and Refund returns captured
+// money. Declined payments are reported in the result rather than as an error;
+// errors mean the provider could not be reached or rejected the request.
+type PaymentProvider interface {
+	Name() string
+	Authorize(amount models.Money, paymentMethod, idempotencyKey string) (*PaymentResult, error)
+	Capture(reference string, amount models.Money) (*PaymentResult, error)
+	Void(reference string) (*PaymentResult, error)
+	Refund(reference string, amount models.Money) (*PaymentResult, error)
+	// VerifyWebhook checks the signature of a webhook request and decodes its event
+	VerifyWebhook(payload []byte, signature string) (*PaymentEvent, error)
+}
+
+// PaymentResult is the outcome of a provider operation
+type PaymentResult struct {
+	Reference     string
+	Status        models.PaymentStatus
+	FailureReason string
+}
+
+// PaymentEvent is a payment status change reported by the provider through a webhook
+type PaymentEvent struct {
+	Reference     string               `json:"reference"`
+	Status        models.PaymentStatus `json:"status"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+}
+
+// Payment method tokens understood by the mock provider
+const (
+	MockPaymentMethodSuccess = "mock_success"
+	MockPaymentMethodDecline = "mock_decline"
+	MockPaymentMethodError   = "mock_error"
+)
+
+// MockPaymentProvider is an in-process fake provider for development and tests.
+// Every payment method is authorized except MockPaymentMethodDecline, which is
+// declined, and MockPaymentMethodError, which fails as if the provider were down.
+// Webhooks are signed with an HMAC-SHA256 of the payload using the shared secret.
+type MockPaymentProvider struct {
+	secret   []byte
+	mu       sync.Mutex
+	payments map[string]*mockPayment
+	byKey    map[string]string
+}
+
+type mockPayment struct {
+	authorized models.Money
+	captured   int64
+	refunded   int64
+	status     models.PaymentStatus
+}
+
+func NewMockPaymentProvider(webhookSecret string) *MockPaymentProvider {
+	return &MockPaymentProvider{
+		secret:   []byte(webhookSecret),
+		payments: make(map[string]*mockPayment),
+		byKey:    make(map[string]string),
+	}
+}
+
+func (p *MockPaymentProvider) Name() string {
+	return "mock"
+}
+
+func (p *MockPaymentProvider) Authorize(amount models.Money, paymentMethod, idempotencyKey string) (*PaymentResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if reference, ok := p.byKey[idempotencyKey]; ok && idempotencyKey != "" {
+		return &PaymentResult{Reference: reference, Status: p.payments[reference].status}, nil
+	}
+	if paymentMethod == MockPaymentMethodError {
+		return nil, errors.New("payment provider unavailable")
+	}
+
+	// References are random so they stay unique across restarts
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	reference := "mock_pay_" + hex.EncodeToString(token)
+	payment := &mockPayment{authorized: amount, status: models.PaymentStatusAuthorized}
+	result := &PaymentResult{Reference: reference, Status: models.PaymentStatusAuthorized}
+	if paymentMethod == MockPaymentMethodDecline {
+		payment.status = models.PaymentStatusFailed
+		result.Status = models.PaymentStatusFailed
+		result.FailureReason = "card declined"
+	}
+	p.payments[reference] = payment
+	if idempotencyKey != "" {
+		p.byKey[idempotencyKey] = reference
+	}
+	return result, nil
+}
+
+func (p *MockPaymentProvider) Capture(reference string, amount models.Money) (*PaymentResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return nil, errors.New("unknown payment")
+	}
+	if payment.status != models.PaymentStatusAuthorized {
+		return nil, fmt.Errorf("cannot capture a %s payment", payment.status)
+	}
+	if !amount.SameCurrency(payment.authorized) || amount.Amount > payment.authorized.Amount {
+		return nil, errors.New("capture exceeds the authorized amount")
+	}
+	payment.captured = amount.Amount
+	payment.status = models.PaymentStatusCaptured
+	return &PaymentResult{Reference: reference, Status: payment.status}, nil
+}
+
+func (p *MockPaymentProvider) Void(reference string) (*PaymentResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return nil, errors.New("unknown payment")
+	}
+	if payment.status != models.PaymentStatusAuthorized {
+		return nil, fmt.Errorf("cannot void a %s payment", payment.status)
+	}
+	payment.status = models.PaymentStatusVoided
+	return &PaymentResult{Reference: reference, Status: payment.status}, nil
+}
+
+func (p *MockPaymentProvider) Refund(reference string, amount models.Money) (*PaymentResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return nil, errors.New("unknown payment")
+	}
+	if payment.status != models.PaymentStatusCaptured {
+		return nil, fmt.Errorf("cannot refund a %s payment", payment.status)
+	}
+	if !amount.SameCurrency(payment.authorized) || payment.refunded+amount.Amount > payment.captured {
+		return nil, errors.New("refund exceeds the captured amount")
+	}
+	payment.refunded += amount.Amount
+	if payment.refunded == payment.captured {
+		payment.status = models.PaymentStatusRefunded
+	}
+	return &PaymentResult{Reference: reference, Status: payment.status}, nil
+}
+
+func (p *MockPaymentProvider) VerifyWebhook(payload []byte, signature string) (*PaymentEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || len(p.secret) == 0 || !hmac.Equal(expected, p.sign(payload)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+	var event PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errors.New("invalid webhook payload")
+	}
+	return &event, nil
+}
+
+// SignWebhook returns the signature the mock provider sends with a webhook payload
+func (p *MockPaymentProvider) SignWebhook(payload []byte) string {
+	return hex.EncodeToString(p.sign(payload))
+}
+
+func (p *MockPaymentProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,186 @@
+package business
+
+import (
+	"testing"
+	"tumdum_backend/models"
+)
+
+func TestMockPaymentProviderAuthorize(t *testing.T) {
+	tests := []struct {
+		name          string
+		paymentMethod string
+		wantStatus    models.PaymentStatus
+		wantErr       bool
+	}{
+		{name: "success", paymentMethod: MockPaymentMethodSuccess, wantStatus: models.PaymentStatusAuthorized},
+		{name: "any other method", paymentMethod: "pm_card_visa", wantStatus: models.PaymentStatusAuthorized},
+		{name: "decline", paymentMethod: MockPaymentMethodDecline, wantStatus: models.PaymentStatusFailed},
+		{name: "provider error", paymentMethod: MockPaymentMethodError, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewMockPaymentProvider("secret")
+			result, err := provider.Authorize(models.NewMoney(2500, "USD"), tt.paymentMethod, "key")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", result.Status, tt.wantStatus)
+			}
+			if result.Reference == "" {
+				t.Error("expected a reference")
+			}
+			if (result.FailureReason != "") != (tt.wantStatus == models.PaymentStatusFailed) {
+				t.Errorf("failure reason = %q", result.FailureReason)
+			}
+		})
+	}
+}
+
+func TestMockPaymentProviderIdempotency(t *testing.T) {
+	provider := NewMockPaymentProvider("secret")
+	first, err := provider.Authorize(models.NewMoney(2500, "USD"), MockPaymentMethodSuccess, "order-1-attempt-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, err := provider.Authorize(models.NewMoney(2500, "USD"), MockPaymentMethodSuccess, "order-1-attempt-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.Reference != first.Reference {
+		t.Errorf("repeated authorization got reference %s, want %s", again.Reference, first.Reference)
+	}
+	other, err := provider.Authorize(models.NewMoney(2500, "USD"), MockPaymentMethodSuccess, "order-1-attempt-2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if other.Reference == first.Reference {
+		t.Error("a new idempotency key must create a new payment")
+	}
+}
+
+func TestMockPaymentProviderSettlement(t *testing.T) {
+	usd := func(amount int64) models.Money { return models.NewMoney(amount, "USD") }
+	type step struct {
+		op         string // capture, void or refund
+		amount     models.Money
+		wantStatus models.PaymentStatus
+		wantErr    bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "capture in full", steps: []step{{op: "capture", amount: usd(2500), wantStatus: models.PaymentStatusCaptured}}},
+		{name: "capture less", steps: []step{{op: "capture", amount: usd(2000), wantStatus: models.PaymentStatusCaptured}}},
+		{name: "capture more", steps: []step{{op: "capture", amount: usd(2600), wantErr: true}}},
+		{name: "capture another currency", steps: []step{{op: "capture", amount: models.NewMoney(2500, "EUR"), wantErr: true}}},
+		{name: "capture twice", steps: []step{
+			{op: "capture", amount: usd(2500), wantStatus: models.PaymentStatusCaptured},
+			{op: "capture", amount: usd(2500), wantErr: true},
+		}},
+		{name: "void", steps: []step{{op: "void", wantStatus: models.PaymentStatusVoided}}},
+		{name: "void after capture", steps: []step{
+			{op: "capture", amount: usd(2500), wantStatus: models.PaymentStatusCaptured},
+			{op: "void", wantErr: true},
+		}},
+		{name: "refund before capture", steps: []step{{op: "refund", amount: usd(500), wantErr: true}}},
+		{name: "refund in parts", steps: []step{
+			{op: "capture", amount: usd(2000), wantStatus: models.PaymentStatusCaptured},
+			{op: "refund", amount: usd(500), wantStatus: models.PaymentStatusCaptured},
+			{op: "refund", amount: usd(1500), wantStatus: models.PaymentStatusRefunded},
+		}},
+		{name: "refund more than captured", steps: []step{
+			{op: "capture", amount: usd(2000), wantStatus: models.PaymentStatusCaptured},
+			{op: "refund", amount: usd(1500), wantStatus: models.PaymentStatusCaptured},
+			{op: "refund", amount: usd(600), wantErr: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewMockPaymentProvider("secret")
+			authorized, err := provider.Authorize(usd(2500), MockPaymentMethodSuccess, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, step := range tt.steps {
+				var result *PaymentResult
+				switch step.op {
+				case "capture":
+					result, err = provider.Capture(authorized.Reference, step.amount)
+				case "void":
+					result, err = provider.Void(authorized.Reference)
+				case "refund":
+					result, err = provider.Refund(authorized.Reference, step.amount)
+				}
+				if step.wantErr {
+					if err == nil {
+						t.Fatalf("step %d (%s): expected an error", i, step.op)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %d (%s): unexpected error: %v", i, step.op, err)
+				}
+				if result.Status != step.wantStatus {
+					t.Errorf("step %d (%s): status = %s, want %s", i, step.op, result.Status, step.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestMockPaymentProviderUnknownPayment(t *testing.T) {
+	provider := NewMockPaymentProvider("secret")
+	if _, err := provider.Capture("mock_pay_unknown", models.NewMoney(100, "USD")); err == nil {
+		t.Error("capture: expected an error")
+	}
+	if _, err := provider.Void("mock_pay_unknown"); err == nil {
+		t.Error("void: expected an error")
+	}
+	if _, err := provider.Refund("mock_pay_unknown", models.NewMoney(100, "USD")); err == nil {
+		t.Error("refund: expected an error")
+	}
+}
+
+func TestMockPaymentProviderVerifyWebhook(t *testing.T) {
+	payload := []byte(`{"reference":"mock_pay_1","status":"CAPTURED"}`)
+	signed := NewMockPaymentProvider("secret").SignWebhook(payload)
+	tests := []struct {
+		name      string
+		secret    string
+		payload   []byte
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid", secret: "secret", payload: payload, signature: signed},
+		{name: "other secret", secret: "other", payload: payload, signature: signed, wantErr: true},
+		{name: "tampered payload", secret: "secret", payload: []byte(`{"reference":"mock_pay_1","status":"REFUNDED"}`), signature: signed, wantErr: true},
+		{name: "not hex", secret: "secret", payload: payload, signature: "zz", wantErr: true},
+		{name: "missing signature", secret: "secret", payload: payload, wantErr: true},
+		{name: "empty secret", secret: "", payload: payload, signature: NewMockPaymentProvider("").SignWebhook(payload), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := NewMockPaymentProvider(tt.secret).VerifyWebhook(tt.payload, tt.signature)
+			if tt.wantErr {
+				if err != ErrInvalidWebhookSignature {
+					t.Fatalf("err = %v, want %v", err, ErrInvalidWebhookSignature)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if event.Reference != "mock_pay_1" || event.Status != models.PaymentStatusCaptured {
+				t.Errorf("event = %+v", event)
+			}
+		})
+	}
+}
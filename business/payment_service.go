@@ -0,0 +1,288 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+type PaymentService struct {
+	uow        *dao.UnitOfWork
+	paymentDAO *dao.PaymentDAO
+	orderDAO   *dao.OrderDAO
+	memberDAO  *dao.RestaurantMemberDAO
+	provider   PaymentProvider
+}
+
+func NewPaymentService(uow *dao.UnitOfWork, paymentDAO *dao.PaymentDAO, orderDAO *dao.OrderDAO, memberDAO *dao.RestaurantMemberDAO, provider PaymentProvider) *PaymentService {
+	return &PaymentService{
+		uow:        uow,
+		paymentDAO: paymentDAO,
+		orderDAO:   orderDAO,
+		memberDAO:  memberDAO,
+		provider:   provider,
+	}
+}
+
+// PayOrder authorizes the order total on the customer's payment method. A declined
+// payment is recorded and returned with status FAILED so the customer can retry.
+func (s *PaymentService) PayOrder(orderID uint, request *models.PaymentRequest, actor Actor) (*models.Payment, error) {
+	var payment *models.Payment
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		// The order row is locked so concurrent attempts cannot both authorize
+		order, err := tx.Orders.GetByIDForUpdate(orderID)
+		if err != nil {
+			return errors.New("order not found")
+		}
+		if order.UserID != actor.UserID {
+			return ErrForbidden
+		}
+		if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusScheduled {
+			return errors.New("only pending or scheduled orders can be paid")
+		}
+		if !order.TotalAmount.IsPositive() {
+			return errors.New("order has nothing to pay")
+		}
+		active, err := tx.Payments.GetActiveByOrderIDForUpdate(orderID)
+		if err != nil {
+			return err
+		}
+		if active != nil {
+			return errors.New("order is already paid")
+		}
+
+		attempts, err := tx.Payments.GetByOrderID(orderID)
+		if err != nil {
+			return err
+		}
+		idempotencyKey := fmt.Sprintf("order-%d-attempt-%d", orderID, len(attempts)+1)
+		result, err := s.provider.Authorize(order.TotalAmount, request.PaymentMethod, idempotencyKey)
+		if err != nil {
+			return fmt.Errorf("payment failed: %w", err)
+		}
+
+		payment = &models.Payment{
+			OrderID:           orderID,
+			Provider:          s.provider.Name(),
+			ProviderReference: result.Reference,
+			Status:            result.Status,
+			Amount:            order.TotalAmount,
+			CapturedAmount:    models.NewMoney(0, order.TotalAmount.Currency),
+			RefundedAmount:    models.NewMoney(0, order.TotalAmount.Currency),
+			FailureReason:     result.FailureReason,
+		}
+		return tx.Payments.Create(payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetPayments returns the payment attempts of an order to its customer, the
+// restaurant's members and admins
+func (s *PaymentService) GetPayments(orderID uint, actor Actor) ([]models.Payment, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.UserID != actor.UserID && checkRestaurantMember(s.memberDAO, actor, order.RestaurantID) != nil {
+		return nil, ErrForbidden
+	}
+	return s.paymentDAO.GetByOrderID(orderID)
+}
+
+// HandleWebhook applies a payment status change reported by the provider
+func (s *PaymentService) HandleWebhook(payload []byte, signature string) error {
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+	switch event.Status {
+	case models.PaymentStatusAuthorized, models.PaymentStatusCaptured, models.PaymentStatusFailed,
+		models.PaymentStatusVoided, models.PaymentStatusRefunded:
+	default:
+		return errors.New("unknown payment status")
+	}
+
+	return s.uow.Do(func(tx *dao.Tx) error {
+		payment, err := tx.Payments.GetByProviderReferenceForUpdate(s.provider.Name(), event.Reference)
+		if err != nil {
+			return errors.New("payment not found")
+		}
+		// Providers may deliver the same event more than once
+		if payment.Status == event.Status {
+			return nil
+		}
+		if !isValidPaymentTransition(payment.Status, event.Status) {
+			return fmt.Errorf("cannot change payment status from %s to %s", payment.Status, event.Status)
+		}
+		payment.Status = event.Status
+		payment.FailureReason = event.FailureReason
+		return tx.Payments.Update(payment)
+	})
+}
+
+func isValidPaymentTransition(current, new models.PaymentStatus) bool {
+	validTransitions := map[models.PaymentStatus][]models.PaymentStatus{
+		models.PaymentStatusAuthorized: {models.PaymentStatusCaptured, models.PaymentStatusVoided},
+		models.PaymentStatusCaptured:   {models.PaymentStatusRefunded},
+	}
+
+	for _, validStatus := range validTransitions[current] {
+		if validStatus == new {
+			return true
+		}
+	}
+	return false
+}
+
+// settleBatchSize bounds how many failed settlements are retried per run
+const settleBatchSize = 100
+
+// SettleOrder moves money once an order reaches a final status: a delivered order
+// is captured and a cancelled one is voided, or refunded if it was already captured.
+// Provider errors leave the payment as it was, with the settlement pending for
+// RetrySettlements.
+func (s *PaymentService) SettleOrder(order *models.Order, status models.OrderStatus) {
+	if status != models.OrderStatusDelivered && status != models.OrderStatusCancelled {
+		return
+	}
+	if err := s.settle(order, status); err != nil {
+		log.Printf("Failed to settle payment of order %d: %v", order.ID, err)
+	}
+}
+
+// RetrySettlements settles again the payments whose capture, void or refund
+// failed and are due for another try, and returns how many succeeded
+func (s *PaymentService) RetrySettlements(now time.Time) (int, error) {
+	payments, err := s.paymentDAO.GetDueSettlements(now, settleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	settled := 0
+	for _, payment := range payments {
+		order, err := s.orderDAO.GetByID(payment.OrderID)
+		if err != nil {
+			log.Printf("Failed to load order %d to settle its payment: %v", payment.OrderID, err)
+			continue
+		}
+		if err := s.settle(order, order.Status); err != nil {
+			log.Printf("Failed to settle payment of order %d (attempt %d): %v", order.ID, payment.SettleAttempts+1, err)
+			continue
+		}
+		settled++
+	}
+	return settled, nil
+}
+
+// settle captures, voids or refunds the active payment of an order for its status.
+// A failure is recorded on the payment with the time of the next try.
+func (s *PaymentService) settle(order *models.Order, status models.OrderStatus) error {
+	var pending *models.Payment
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		payment, err := tx.Payments.GetActiveByOrderIDForUpdate(order.ID)
+		if err != nil || payment == nil {
+			return err
+		}
+		pending = payment
+
+		var result *PaymentResult
+		switch {
+		case status == models.OrderStatusDelivered && payment.Status == models.PaymentStatusAuthorized:
+			amount := order.TotalAmount
+			if amount.Amount > payment.Amount.Amount {
+				amount = payment.Amount
+			}
+			if result, err = s.provider.Capture(payment.ProviderReference, amount); err != nil {
+				return err
+			}
+			payment.CapturedAmount = amount
+		case status == models.OrderStatusCancelled && payment.Status == models.PaymentStatusAuthorized:
+			if result, err = s.provider.Void(payment.ProviderReference); err != nil {
+				return err
+			}
+		case status == models.OrderStatusCancelled && payment.Status == models.PaymentStatusCaptured &&
+			payment.RefundedAmount.Amount < payment.CapturedAmount.Amount:
+			amount := models.NewMoney(payment.CapturedAmount.Amount-payment.RefundedAmount.Amount, payment.CapturedAmount.Currency)
+			if result, err = s.provider.Refund(payment.ProviderReference, amount); err != nil {
+				return err
+			}
+			payment.RefundedAmount = payment.RefundedAmount.Add(amount)
+		default:
+			// Nothing to do, unless a retry finds the payment was settled in the meantime
+			if payment.SettleRetryAt == nil {
+				return nil
+			}
+		}
+
+		if result != nil {
+			payment.Status = result.Status
+		}
+		payment.SettleError = ""
+		payment.SettleAttempts = 0
+		payment.SettleRetryAt = nil
+		return tx.Payments.Update(payment)
+	})
+	if err != nil && pending != nil {
+		retryAt := time.Now().Add(settleRetryDelay(pending.SettleAttempts + 1))
+		if recordErr := s.paymentDAO.RecordSettleFailure(pending.ID, err.Error(), retryAt); recordErr != nil {
+			log.Printf("Failed to record the failed settlement of payment %d: %v", pending.ID, recordErr)
+		}
+	}
+	return err
+}
+
+// settleRetryDelay is how long to wait before settling a payment again after the
+// given number of failed attempts, growing quadratically up to an hour
+func settleRetryDelay(attempts int) time.Duration {
+	return min(time.Duration(attempts*attempts)*time.Minute, time.Hour)
+}
+
+// refundCaptured pays back up to amount of an order's captured payment. Payments
+// that are only authorized are left alone, as just the reduced order total will
+// be captured on delivery.
+func (s *PaymentService) refundCaptured(tx *dao.Tx, orderID uint, amount models.Money) error {
+	payment, err := tx.Payments.GetActiveByOrderIDForUpdate(orderID)
+	if err != nil || payment == nil || payment.Status != models.PaymentStatusCaptured {
+		return err
+	}
+	remaining := payment.CapturedAmount.Amount - payment.RefundedAmount.Amount
+	if amount.Amount > remaining {
+		amount = models.NewMoney(remaining, payment.CapturedAmount.Currency)
+	}
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	result, err := s.provider.Refund(payment.ProviderReference, amount)
+	if err != nil {
+		return fmt.Errorf("refund failed: %w", err)
+	}
+	payment.RefundedAmount = payment.RefundedAmount.Add(amount)
+	payment.Status = result.Status
+	return tx.Payments.Update(payment)
+}
+
+// requirePaidOrder rejects confirming an order that has no authorized payment
+// covering its total. Orders discounted down to nothing need no payment.
+func requirePaidOrder(tx *dao.Tx, order *models.Order) error {
+	if !order.TotalAmount.IsPositive() {
+		return nil
+	}
+	payment, err := tx.Payments.GetActiveByOrderIDForUpdate(order.ID)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		return errors.New("order has not been paid")
+	}
+	if !payment.Amount.SameCurrency(order.TotalAmount) || payment.Amount.Amount < order.TotalAmount.Amount {
+		return errors.New("payment does not cover the order total")
+	}
+	return nil
+}
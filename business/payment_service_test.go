@@ -0,0 +1,50 @@
+package business
+
+import (
+	"testing"
+	"time"
+	"tumdum_backend/models"
+)
+
+func TestIsValidPaymentTransition(t *testing.T) {
+	tests := []struct {
+		current, new models.PaymentStatus
+		want         bool
+	}{
+		{models.PaymentStatusAuthorized, models.PaymentStatusCaptured, true},
+		{models.PaymentStatusAuthorized, models.PaymentStatusVoided, true},
+		{models.PaymentStatusCaptured, models.PaymentStatusRefunded, true},
+		{models.PaymentStatusAuthorized, models.PaymentStatusRefunded, false},
+		{models.PaymentStatusCaptured, models.PaymentStatusAuthorized, false},
+		{models.PaymentStatusCaptured, models.PaymentStatusVoided, false},
+		{models.PaymentStatusVoided, models.PaymentStatusCaptured, false},
+		{models.PaymentStatusRefunded, models.PaymentStatusCaptured, false},
+		{models.PaymentStatusFailed, models.PaymentStatusCaptured, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.current)+"_to_"+string(tt.new), func(t *testing.T) {
+			if got := isValidPaymentTransition(tt.current, tt.new); got != tt.want {
+				t.Errorf("isValidPaymentTransition(%s, %s) = %v, want %v", tt.current, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettleRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 4 * time.Minute},
+		{5, 25 * time.Minute},
+		{7, 49 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := settleRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("settleRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
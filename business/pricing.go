@@ -0,0 +1,80 @@
+package business
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"tumdum_backend/config"
+	"tumdum_backend/models"
+)
+
+// OrderPricer works out the price breakdown of orders: the items subtotal, the
+// discounts, tax on the discounted subtotal, the restaurant's delivery and
+// packaging fees and the customer's tip, which add up to the order total.
+type OrderPricer struct {
+	config config.PricingConfig
+}
+
+func NewOrderPricer(cfg config.PricingConfig) *OrderPricer {
+	return &OrderPricer{config: cfg}
+}
+
+// ApplyCharges sets the tax rate and fees of a new order from its restaurant and
+// checks the tip. They are kept with the order, so later changes to the
+// restaurant's fees or the tax rates do not reprice it.
+func (p *OrderPricer) ApplyCharges(order *models.Order, restaurant *models.Restaurant) error {
+	currency := restaurant.Currency
+	order.TaxRate = p.config.TaxRate(restaurant.Country, restaurant.State)
+
+	// Restaurants with a per-km rate charge it on top of the flat fee when the
+	// delivery distance is known
+	deliveryFee := models.NewMoney(restaurant.DeliveryFee.Amount, currency)
+	if order.DeliveryDistanceKm != nil && restaurant.DeliveryFeePerKm.IsPositive() {
+		distanceFee := math.Round(float64(restaurant.DeliveryFeePerKm.Amount) * *order.DeliveryDistanceKm)
+		deliveryFee = deliveryFee.Add(models.NewMoney(int64(distanceFee), currency))
+	}
+	order.DeliveryFee = deliveryFee
+	order.PackagingFee = models.NewMoney(restaurant.PackagingFee.Amount, currency)
+
+	order.TipAmount.Currency = strings.ToUpper(strings.TrimSpace(order.TipAmount.Currency))
+	if order.TipAmount.Currency == "" {
+		order.TipAmount.Currency = currency
+	}
+	if order.TipAmount.Currency != currency {
+		return errors.New("tip currency must match the restaurant currency")
+	}
+	if order.TipAmount.Amount < 0 {
+		return errors.New("tip cannot be negative")
+	}
+	return nil
+}
+
+// Total works out the order's subtotal from the items not refunded, the amounts
+// of its discount lines and its tax, and sets the order total. An order with no
+// items left is not charged its fees or tip either.
+func (p *OrderPricer) Total(order *models.Order) {
+	currency := order.SubtotalAmount.Currency
+	subtotal := models.NewMoney(0, currency)
+	remaining := 0
+	for i := range order.OrderItems {
+		subtotal = subtotal.Add(order.OrderItems[i].RemainingTotal())
+		remaining += order.OrderItems[i].RemainingQuantity()
+	}
+	discount := applyDiscounts(order, subtotal)
+	taxable := subtotal.Amount - discount.Amount
+
+	order.SubtotalAmount = subtotal
+	order.DiscountAmount = discount
+	order.TaxAmount = models.NewMoney(int64(math.Round(float64(taxable)*order.TaxRate/100)), currency)
+	if remaining == 0 {
+		order.DeliveryFee = models.NewMoney(0, currency)
+		order.PackagingFee = models.NewMoney(0, currency)
+		order.TipAmount = models.NewMoney(0, currency)
+	}
+
+	order.TotalAmount = models.NewMoney(taxable, currency).
+		Add(order.TaxAmount).
+		Add(order.DeliveryFee).
+		Add(order.PackagingFee).
+		Add(order.TipAmount)
+}
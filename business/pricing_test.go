@@ -0,0 +1,75 @@
+package business
+
+import (
+	"testing"
+	"tumdum_backend/config"
+	"tumdum_backend/models"
+)
+
+func TestOrderPricerTotal(t *testing.T) {
+	usd := func(amount int64) models.Money { return models.NewMoney(amount, "USD") }
+	percentOff := func(percent int) models.OrderDiscount {
+		return models.OrderDiscount{DiscountType: models.DiscountTypePercentage, PercentOff: percent}
+	}
+	amountOff := func(amount int64) models.OrderDiscount {
+		return models.OrderDiscount{DiscountType: models.DiscountTypeFixedAmount, AmountOff: usd(amount)}
+	}
+
+	tests := []struct {
+		name         string
+		refunded     [2]int // portions refunded of each line
+		taxRate      float64
+		fees         int64 // delivery fee, packaging fee and tip, each
+		discounts    []models.OrderDiscount
+		wantSubtotal int64
+		wantDiscount int64
+		wantTax      int64
+		wantTotal    int64
+	}{
+		{name: "items only", wantSubtotal: 3000, wantTotal: 3000},
+		{name: "tax", taxRate: 7.25, wantSubtotal: 3000, wantTax: 218, wantTotal: 3218},
+		{name: "fees and tip", fees: 100, wantSubtotal: 3000, wantTotal: 3300},
+		{name: "tax on the discounted subtotal", taxRate: 10, discounts: []models.OrderDiscount{percentOff(20)},
+			wantSubtotal: 3000, wantDiscount: 600, wantTax: 240, wantTotal: 2640},
+		{name: "fixed discount", fees: 100, discounts: []models.OrderDiscount{amountOff(500)},
+			wantSubtotal: 3000, wantDiscount: 500, wantTotal: 2800},
+		{name: "fully discounted", taxRate: 10, discounts: []models.OrderDiscount{percentOff(100)},
+			wantSubtotal: 3000, wantDiscount: 3000, wantTotal: 0},
+		{name: "discount above subtotal keeps fees", fees: 100, discounts: []models.OrderDiscount{amountOff(5000)},
+			wantSubtotal: 3000, wantDiscount: 3000, wantTotal: 300},
+		{name: "partly refunded", refunded: [2]int{1, 0}, taxRate: 10, fees: 100,
+			wantSubtotal: 2000, wantTax: 200, wantTotal: 2500},
+		{name: "fully refunded drops fees and tip", refunded: [2]int{2, 1}, taxRate: 10, fees: 100, discounts: []models.OrderDiscount{amountOff(500)},
+			wantSubtotal: 0, wantDiscount: 0, wantTax: 0, wantTotal: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{
+				SubtotalAmount: usd(0),
+				TaxRate:        tt.taxRate,
+				DeliveryFee:    usd(tt.fees),
+				PackagingFee:   usd(tt.fees),
+				TipAmount:      usd(tt.fees),
+				Discounts:      tt.discounts,
+				OrderItems: []models.OrderItem{
+					{Quantity: 2, RefundedQuantity: tt.refunded[0], LineTotal: usd(2000)},
+					{Quantity: 1, RefundedQuantity: tt.refunded[1], LineTotal: usd(1000)},
+				},
+			}
+			NewOrderPricer(config.PricingConfig{}).Total(order)
+
+			if order.SubtotalAmount != usd(tt.wantSubtotal) {
+				t.Errorf("subtotal = %v, want %d", order.SubtotalAmount, tt.wantSubtotal)
+			}
+			if order.DiscountAmount != usd(tt.wantDiscount) {
+				t.Errorf("discount = %v, want %d", order.DiscountAmount, tt.wantDiscount)
+			}
+			if order.TaxAmount != usd(tt.wantTax) {
+				t.Errorf("tax = %v, want %d", order.TaxAmount, tt.wantTax)
+			}
+			if order.TotalAmount != usd(tt.wantTotal) {
+				t.Errorf("total = %v, want %d", order.TotalAmount, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package business
+
+import (
+	"errors"
+	"strings"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+type PromotionService struct {
+	promotionDAO  *dao.PromotionDAO
+	restaurantDAO *dao.RestaurantDAO
+}
+
+func NewPromotionService(promotionDAO *dao.PromotionDAO, restaurantDAO *dao.RestaurantDAO) *PromotionService {
+	return &PromotionService{
+		promotionDAO:  promotionDAO,
+		restaurantDAO: restaurantDAO,
+	}
+}
+
+func (s *PromotionService) GetPromotions(filter models.PromotionFilter, page models.PageRequest) (*models.Page[models.Promotion], error) {
+	filter.Code = models.NormalizePromoCode(filter.Code)
+	return s.promotionDAO.GetAll(filter, page)
+}
+
+func (s *PromotionService) GetPromotion(id uint) (*models.Promotion, error) {
+	promotion, err := s.promotionDAO.GetByID(id)
+	if err != nil {
+		return nil, errors.New("promotion not found")
+	}
+	return promotion, nil
+}
+
+func (s *PromotionService) CreatePromotion(promotion *models.Promotion) error {
+	if err := s.validatePromotion(promotion); err != nil {
+		return err
+	}
+	promotion.ID = 0
+	return s.promotionDAO.Create(promotion)
+}
+
+func (s *PromotionService) UpdatePromotion(promotion *models.Promotion) error {
+	existing, err := s.promotionDAO.GetByID(promotion.ID)
+	if err != nil {
+		return errors.New("promotion not found")
+	}
+	if err := s.validatePromotion(promotion); err != nil {
+		return err
+	}
+	promotion.CreatedAt = existing.CreatedAt
+	return s.promotionDAO.Update(promotion)
+}
+
+func (s *PromotionService) DeletePromotion(id uint) error {
+	if _, err := s.promotionDAO.GetByID(id); err != nil {
+		return errors.New("promotion not found")
+	}
+	return s.promotionDAO.Delete(id)
+}
+
+// validatePromotion checks the code, discount and limits of a promotion. Amounts
+// without a currency are in the restaurant's currency, or the default currency
+// for platform-wide promotions.
+func (s *PromotionService) validatePromotion(promotion *models.Promotion) error {
+	promotion.Code = models.NormalizePromoCode(promotion.Code)
+	if promotion.Code == "" {
+		return errors.New("promo code is required")
+	}
+	if len(promotion.Code) > 50 {
+		return errors.New("promo code cannot be longer than 50 characters")
+	}
+	if existing, err := s.promotionDAO.GetByCode(promotion.Code); err == nil && existing.ID != promotion.ID {
+		return errors.New("promo code already exists")
+	}
+
+	currency := models.DefaultCurrency
+	if promotion.RestaurantID != nil {
+		restaurant, err := s.restaurantDAO.GetByID(*promotion.RestaurantID)
+		if err != nil {
+			return errors.New("restaurant not found")
+		}
+		currency = restaurant.Currency
+	}
+	for _, amount := range []*models.Money{&promotion.AmountOff, &promotion.MinOrderValue} {
+		amount.Currency = strings.ToUpper(strings.TrimSpace(amount.Currency))
+		if amount.Currency == "" {
+			amount.Currency = currency
+		}
+		if len(amount.Currency) != 3 {
+			return errors.New("invalid currency code")
+		}
+		if promotion.RestaurantID != nil && amount.Currency != currency {
+			return errors.New("promotion currency must match the restaurant currency")
+		}
+		if amount.Amount < 0 {
+			return errors.New("promotion amounts cannot be negative")
+		}
+	}
+
+	switch promotion.DiscountType {
+	case models.DiscountTypePercentage:
+		if promotion.PercentOff < 1 || promotion.PercentOff > 100 {
+			return errors.New("percent_off must be between 1 and 100")
+		}
+		promotion.AmountOff.Amount = 0
+	case models.DiscountTypeFixedAmount:
+		if !promotion.AmountOff.IsPositive() {
+			return errors.New("amount_off must be positive")
+		}
+		promotion.PercentOff = 0
+	default:
+		return errors.New("discount_type must be PERCENTAGE or FIXED_AMOUNT")
+	}
+
+	if promotion.MaxRedemptions != nil && *promotion.MaxRedemptions < 1 {
+		return errors.New("max_redemptions must be positive")
+	}
+	if promotion.MaxRedemptionsPerUser != nil && *promotion.MaxRedemptionsPerUser < 1 {
+		return errors.New("max_redemptions_per_user must be positive")
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
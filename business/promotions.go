@@ -0,0 +1,133 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// applyPromotion checks the order's promo code against the promotion's rules at
+// the given time and adds its discount line to the order, whose amount is worked
+// out with the order total. It returns the promotion to redeem once the order is
+// saved, or nil when the order has no promo code.
+func applyPromotion(tx *dao.Tx, order *models.Order, now time.Time) (*models.Promotion, error) {
+	order.Discounts = nil
+	code := models.NormalizePromoCode(order.PromoCode)
+	if code == "" {
+		return nil, nil
+	}
+
+	promotion, err := tx.Promotions.GetByCodeForUpdate(code)
+	if err != nil {
+		return nil, errors.New("invalid promo code")
+	}
+	if !promotion.IsActive {
+		return nil, errors.New("promo code is not active")
+	}
+	if promotion.StartsAt != nil && now.Before(*promotion.StartsAt) {
+		return nil, errors.New("promo code is not valid yet")
+	}
+	if promotion.EndsAt != nil && !now.Before(*promotion.EndsAt) {
+		return nil, errors.New("promo code has expired")
+	}
+	if promotion.RestaurantID != nil && *promotion.RestaurantID != order.RestaurantID {
+		return nil, errors.New("promo code is not valid for this restaurant")
+	}
+
+	subtotal := order.SubtotalAmount
+	if promotion.DiscountType == models.DiscountTypeFixedAmount && !promotion.AmountOff.SameCurrency(subtotal) {
+		return nil, errors.New("promo code is not valid for this currency")
+	}
+	if promotion.MinOrderValue.IsPositive() {
+		if !promotion.MinOrderValue.SameCurrency(subtotal) {
+			return nil, errors.New("promo code is not valid for this currency")
+		}
+		if subtotal.Amount < promotion.MinOrderValue.Amount {
+			return nil, fmt.Errorf("promo code requires a minimum order of %s", promotion.MinOrderValue)
+		}
+	}
+
+	if promotion.FirstOrderOnly {
+		orders, err := tx.Orders.CountActiveByUserID(order.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if orders > 0 {
+			return nil, errors.New("promo code is only valid on a first order")
+		}
+	}
+	if promotion.MaxRedemptions != nil {
+		redemptions, err := tx.Promotions.CountRedemptions(promotion.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		if redemptions >= int64(*promotion.MaxRedemptions) {
+			return nil, errors.New("promo code has been fully redeemed")
+		}
+	}
+	if promotion.MaxRedemptionsPerUser != nil {
+		redemptions, err := tx.Promotions.CountRedemptions(promotion.ID, order.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if redemptions >= int64(*promotion.MaxRedemptionsPerUser) {
+			return nil, errors.New("promo code has already been used")
+		}
+	}
+
+	promotionID := promotion.ID
+	order.Discounts = []models.OrderDiscount{{
+		PromotionID:  &promotionID,
+		Code:         promotion.Code,
+		Description:  promotion.Description,
+		DiscountType: promotion.DiscountType,
+		PercentOff:   promotion.PercentOff,
+		AmountOff:    promotion.AmountOff,
+	}}
+	return promotion, nil
+}
+
+// redeemPromotion records the use of a promotion by a saved order
+func redeemPromotion(tx *dao.Tx, promotion *models.Promotion, order *models.Order) error {
+	if promotion == nil {
+		return nil
+	}
+	return tx.Promotions.CreateRedemption(&models.PromotionRedemption{
+		PromotionID: promotion.ID,
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+		Discount:    order.DiscountAmount,
+	})
+}
+
+// applyDiscounts works out the amounts of the order's discount lines for the
+// subtotal and returns their sum, which never exceeds the subtotal
+func applyDiscounts(order *models.Order, subtotal models.Money) models.Money {
+	total := models.NewMoney(0, subtotal.Currency)
+	for i := range order.Discounts {
+		remaining := models.NewMoney(subtotal.Amount-total.Amount, subtotal.Currency)
+		order.Discounts[i].Amount = discountAmount(&order.Discounts[i], remaining)
+		total = total.Add(order.Discounts[i].Amount)
+	}
+	return total
+}
+
+// discountAmount is the discount of a line on the subtotal, at most the subtotal
+func discountAmount(discount *models.OrderDiscount, subtotal models.Money) models.Money {
+	var amount int64
+	switch discount.DiscountType {
+	case models.DiscountTypePercentage:
+		amount = subtotal.Amount * int64(discount.PercentOff) / 100
+	case models.DiscountTypeFixedAmount:
+		amount = discount.AmountOff.Amount
+	}
+	if amount > subtotal.Amount {
+		amount = subtotal.Amount
+	}
+	if amount < 0 {
+		amount = 0
+	}
+	return models.NewMoney(amount, subtotal.Currency)
+}
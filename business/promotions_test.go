@@ -0,0 +1,51 @@
+package business
+
+import (
+	"testing"
+	"tumdum_backend/models"
+)
+
+func TestDiscountAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		discount models.OrderDiscount
+		subtotal int64
+		want     int64
+	}{
+		{name: "percentage", discount: models.OrderDiscount{DiscountType: models.DiscountTypePercentage, PercentOff: 15}, subtotal: 2000, want: 300},
+		{name: "percentage rounds down", discount: models.OrderDiscount{DiscountType: models.DiscountTypePercentage, PercentOff: 10}, subtotal: 999, want: 99},
+		{name: "whole subtotal", discount: models.OrderDiscount{DiscountType: models.DiscountTypePercentage, PercentOff: 100}, subtotal: 2000, want: 2000},
+		{name: "fixed amount", discount: models.OrderDiscount{DiscountType: models.DiscountTypeFixedAmount, AmountOff: models.NewMoney(500, "USD")}, subtotal: 2000, want: 500},
+		{name: "fixed amount above subtotal", discount: models.OrderDiscount{DiscountType: models.DiscountTypeFixedAmount, AmountOff: models.NewMoney(2500, "USD")}, subtotal: 2000, want: 2000},
+		{name: "negative fixed amount", discount: models.OrderDiscount{DiscountType: models.DiscountTypeFixedAmount, AmountOff: models.NewMoney(-500, "USD")}, subtotal: 2000, want: 0},
+		{name: "empty subtotal", discount: models.OrderDiscount{DiscountType: models.DiscountTypeFixedAmount, AmountOff: models.NewMoney(500, "USD")}, subtotal: 0, want: 0},
+		{name: "unknown type", discount: models.OrderDiscount{DiscountType: "BOGUS", PercentOff: 50}, subtotal: 2000, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := discountAmount(&tt.discount, models.NewMoney(tt.subtotal, "USD"))
+			if got != models.NewMoney(tt.want, "USD") {
+				t.Errorf("discountAmount() = %v, want %d USD", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDiscountsStacked(t *testing.T) {
+	order := &models.Order{Discounts: []models.OrderDiscount{
+		{DiscountType: models.DiscountTypeFixedAmount, AmountOff: models.NewMoney(1500, "USD")},
+		{DiscountType: models.DiscountTypePercentage, PercentOff: 50},
+		{DiscountType: models.DiscountTypeFixedAmount, AmountOff: models.NewMoney(1000, "USD")},
+	}}
+	total := applyDiscounts(order, models.NewMoney(2000, "USD"))
+	// Each line applies to what the lines before it left, down to nothing
+	want := []int64{1500, 250, 250}
+	for i, discount := range order.Discounts {
+		if discount.Amount.Amount != want[i] {
+			t.Errorf("discount %d = %d, want %d", i, discount.Amount.Amount, want[i])
+		}
+	}
+	if total.Amount != 2000 {
+		t.Errorf("total discount = %d, want 2000", total.Amount)
+	}
+}
@@ -0,0 +1,351 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// RefundService refunds and cancels items of orders. Customers request refunds
+// that the restaurant's owners and managers, or an admin, approve or reject;
+// refunds they request themselves are approved at once.
+type RefundService struct {
+	uow       *dao.UnitOfWork
+	refundDAO *dao.RefundDAO
+	orderDAO  *dao.OrderDAO
+	memberDAO *dao.RestaurantMemberDAO
+	payments  *PaymentService
+	pricer    *OrderPricer
+	events    *OrderEventHub
+}
+
+func NewRefundService(uow *dao.UnitOfWork, refundDAO *dao.RefundDAO, orderDAO *dao.OrderDAO, memberDAO *dao.RestaurantMemberDAO, payments *PaymentService, pricer *OrderPricer, events *OrderEventHub) *RefundService {
+	return &RefundService{
+		uow:       uow,
+		refundDAO: refundDAO,
+		orderDAO:  orderDAO,
+		memberDAO: memberDAO,
+		payments:  payments,
+		pricer:    pricer,
+		events:    events,
+	}
+}
+
+// RequestRefund records a refund of the requested items, or of every item not
+// refunded yet when none are given
+func (s *RefundService) RequestRefund(orderID uint, request *models.RefundRequest, actor Actor) (*models.Refund, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	approver := s.canReview(order, actor)
+	if !approver && order.UserID != actor.UserID {
+		return nil, ErrForbidden
+	}
+	if !request.ReasonCode.IsValid() {
+		return nil, errors.New("invalid reason code")
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return nil, errors.New("cancelled orders cannot be refunded")
+	}
+
+	items, amount, err := refundItems(order, request.Items)
+	if err != nil {
+		return nil, err
+	}
+	refund := &models.Refund{
+		OrderID:     order.ID,
+		RequestedBy: actor.UserID,
+		ReasonCode:  request.ReasonCode,
+		Note:        request.Note,
+		Status:      models.RefundStatusRequested,
+		Amount:      amount,
+		Items:       items,
+	}
+
+	var cancelled *models.Order
+	err = s.uow.Do(func(tx *dao.Tx) error {
+		if err := tx.Refunds.Create(refund); err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("refund of %s requested: %s", refund.Amount, refund.ReasonCode)
+		if err := tx.Orders.CreateStatusEvent(newRefundEvent(order, refund, reason, actor)); err != nil {
+			return err
+		}
+		if !approver {
+			return nil
+		}
+		cancelled, err = s.approve(tx, refund, "", actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cancelled != nil {
+		s.settleCancelled(cancelled)
+	}
+	return refund, nil
+}
+
+// GetRefunds returns the refunds of an order to its customer, the restaurant's
+// members and admins
+func (s *RefundService) GetRefunds(orderID uint, actor Actor) ([]models.Refund, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.UserID != actor.UserID && checkRestaurantMember(s.memberDAO, actor, order.RestaurantID) != nil {
+		return nil, ErrForbidden
+	}
+	return s.refundDAO.GetByOrderID(orderID)
+}
+
+// ApproveRefund takes the refunded items off the order and pays back captured
+// money. An order refunded in full before delivery is cancelled.
+func (s *RefundService) ApproveRefund(orderID, refundID uint, review *models.RefundReview, actor Actor) (*models.Refund, error) {
+	var refund *models.Refund
+	var cancelled *models.Order
+	err := s.review(orderID, refundID, actor, func(tx *dao.Tx, r *models.Refund) error {
+		var err error
+		refund = r
+		cancelled, err = s.approve(tx, refund, review.Note, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cancelled != nil {
+		s.settleCancelled(cancelled)
+	}
+	return refund, nil
+}
+
+// RejectRefund closes a requested refund without changing the order
+func (s *RefundService) RejectRefund(orderID, refundID uint, review *models.RefundReview, actor Actor) (*models.Refund, error) {
+	var refund *models.Refund
+	err := s.review(orderID, refundID, actor, func(tx *dao.Tx, r *models.Refund) error {
+		refund = r
+		order, err := tx.Orders.GetByIDForUpdate(refund.OrderID)
+		if err != nil {
+			return err
+		}
+		markReviewed(refund, models.RefundStatusRejected, review.Note, actor)
+		if err := tx.Refunds.Update(refund); err != nil {
+			return err
+		}
+		return tx.Orders.CreateStatusEvent(newRefundEvent(order, refund, reviewReason("refund rejected", review.Note), actor))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// review checks the actor may review refunds of the order and runs fn with the
+// requested refund locked
+func (s *RefundService) review(orderID, refundID uint, actor Actor, fn func(tx *dao.Tx, refund *models.Refund) error) error {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return errors.New("order not found")
+	}
+	if !s.canReview(order, actor) {
+		return ErrForbidden
+	}
+
+	return s.uow.Do(func(tx *dao.Tx) error {
+		refund, err := tx.Refunds.GetByIDForUpdate(refundID)
+		if err != nil || refund.OrderID != orderID {
+			return errors.New("refund not found")
+		}
+		if refund.Status != models.RefundStatusRequested {
+			return errors.New("refund has already been reviewed")
+		}
+		return fn(tx, refund)
+	})
+}
+
+// approve applies a refund to its order and returns the order, still in its
+// previous status, if the refund cancelled it, so its payment can be settled once
+// the transaction commits
+func (s *RefundService) approve(tx *dao.Tx, refund *models.Refund, note string, actor Actor) (*models.Order, error) {
+	// The order row is locked so concurrent refunds cannot refund an item twice
+	if _, err := tx.Orders.GetByIDForUpdate(refund.OrderID); err != nil {
+		return nil, errors.New("order not found")
+	}
+	order, err := tx.Orders.GetByID(refund.OrderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.Status == models.OrderStatusCancelled {
+		return nil, errors.New("cancelled orders cannot be refunded")
+	}
+
+	quantities, returned, remaining, err := refundOrderItems(order, refund, s.pricer)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Orders.RefundItems(order.ID, quantities); err != nil {
+		return nil, err
+	}
+	if err := tx.Orders.UpdateTotals(order); err != nil {
+		return nil, err
+	}
+	if err := tx.Orders.UpdateDiscounts(order.Discounts); err != nil {
+		return nil, err
+	}
+
+	// Portions of items cancelled before the kitchen started on them can be sold again
+	if order.StockReservedAt != nil && (order.Status == models.OrderStatusPending || order.Status == models.OrderStatusConfirmed) {
+		if err := returnStock(tx, returned, *order.StockReservedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	markReviewed(refund, models.RefundStatusApproved, note, actor)
+	if err := tx.Refunds.Update(refund); err != nil {
+		return nil, err
+	}
+	if err := tx.Orders.CreateStatusEvent(newRefundEvent(order, refund, reviewReason("refund approved", note), actor)); err != nil {
+		return nil, err
+	}
+
+	// An order with nothing left to deliver is cancelled, which releases its payment
+	if remaining == 0 && order.Status != models.OrderStatusDelivered {
+		if err := tx.Orders.UpdateStatus(order.ID, order.Status, models.OrderStatusCancelled); err != nil {
+			return nil, err
+		}
+		event := newStatusEvent(order.ID, order.Status, models.OrderStatusCancelled, "refunded in full", actor)
+		event.RefundID = &refund.ID
+		if err := tx.Orders.CreateStatusEvent(event); err != nil {
+			return nil, err
+		}
+		if err := releaseStock(tx, order); err != nil {
+			return nil, err
+		}
+		if err := tx.Promotions.DeleteRedemptionsByOrderID(order.ID); err != nil {
+			return nil, err
+		}
+		return order, nil
+	}
+	return nil, s.payments.refundCaptured(tx, order.ID, refund.Amount)
+}
+
+// refundOrderItems takes the refund's quantities off the order's items and works
+// out the order total again. It returns the refunded quantities keyed by order
+// item ID, the items returned and the quantity left on the order. Discounts and
+// tax shrink with the subtotal, so the refund amount is what the total drops by.
+func refundOrderItems(order *models.Order, refund *models.Refund, pricer *OrderPricer) (map[uint]int, []models.OrderItem, int, error) {
+	itemByID := make(map[uint]*models.OrderItem, len(order.OrderItems))
+	for i := range order.OrderItems {
+		itemByID[order.OrderItems[i].ID] = &order.OrderItems[i]
+	}
+	quantities := make(map[uint]int, len(refund.Items))
+	returned := make([]models.OrderItem, 0, len(refund.Items))
+	for _, refundItem := range refund.Items {
+		item, ok := itemByID[refundItem.OrderItemID]
+		if !ok {
+			return nil, nil, 0, errors.New("order item not found")
+		}
+		if refundItem.Quantity > item.RemainingQuantity() {
+			return nil, nil, 0, errors.New("refund exceeds the remaining quantity")
+		}
+		item.RefundedQuantity += refundItem.Quantity
+		quantities[item.ID] = refundItem.Quantity
+		returned = append(returned, models.OrderItem{DishID: item.DishID, Quantity: refundItem.Quantity})
+	}
+
+	remaining := 0
+	for _, item := range order.OrderItems {
+		remaining += item.RemainingQuantity()
+	}
+	previousTotal := order.TotalAmount
+	pricer.Total(order)
+	refund.Amount = models.NewMoney(previousTotal.Amount-order.TotalAmount.Amount, order.TotalAmount.Currency)
+	return quantities, returned, remaining, nil
+}
+
+// settleCancelled releases the payment of an order a refund cancelled and
+// announces the cancellation
+func (s *RefundService) settleCancelled(order *models.Order) {
+	s.payments.SettleOrder(order, models.OrderStatusCancelled)
+	s.events.publishStatusChange(order, order.Status, models.OrderStatusCancelled, "refunded in full")
+}
+
+// canReview reports whether the actor may approve refunds of the order
+func (s *RefundService) canReview(order *models.Order, actor Actor) bool {
+	return checkRestaurantMember(s.memberDAO, actor, order.RestaurantID, models.MemberRoleOwner, models.MemberRoleManager) == nil
+}
+
+// refundItems prices the requested quantities of the order's items. Without
+// requested items every quantity not refunded yet is included.
+func refundItems(order *models.Order, requested []models.RefundItemRequest) ([]models.RefundItem, models.Money, error) {
+	amount := models.NewMoney(0, order.TotalAmount.Currency)
+	if len(requested) == 0 {
+		for _, item := range order.OrderItems {
+			if item.RemainingQuantity() > 0 {
+				requested = append(requested, models.RefundItemRequest{OrderItemID: item.ID, Quantity: item.RemainingQuantity()})
+			}
+		}
+		if len(requested) == 0 {
+			return nil, amount, errors.New("order has nothing left to refund")
+		}
+	}
+
+	itemByID := make(map[uint]models.OrderItem, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		itemByID[item.ID] = item
+	}
+	items := make([]models.RefundItem, 0, len(requested))
+	seen := make(map[uint]bool, len(requested))
+	for _, request := range requested {
+		item, ok := itemByID[request.OrderItemID]
+		if !ok {
+			return nil, amount, errors.New("order item not found")
+		}
+		if seen[item.ID] {
+			return nil, amount, errors.New("order item is listed more than once")
+		}
+		seen[item.ID] = true
+		if request.Quantity <= 0 {
+			return nil, amount, errors.New("refund quantity must be positive")
+		}
+		if request.Quantity > item.RemainingQuantity() {
+			return nil, amount, errors.New("refund exceeds the remaining quantity")
+		}
+
+		itemAmount := models.NewMoney(item.LineTotal.Amount/int64(item.Quantity), item.LineTotal.Currency).
+			Multiply(int64(request.Quantity))
+		amount = amount.Add(itemAmount)
+		items = append(items, models.RefundItem{
+			OrderItemID: item.ID,
+			Quantity:    request.Quantity,
+			Amount:      itemAmount,
+		})
+	}
+	return items, amount, nil
+}
+
+func markReviewed(refund *models.Refund, status models.RefundStatus, note string, actor Actor) {
+	now := time.Now()
+	reviewerID := actor.UserID
+	refund.Status = status
+	refund.ReviewedBy = &reviewerID
+	refund.ReviewedAt = &now
+	refund.ReviewNote = note
+}
+
+// newRefundEvent records a refund in the order timeline without changing its status
+func newRefundEvent(order *models.Order, refund *models.Refund, reason string, actor Actor) *models.OrderStatusEvent {
+	event := newStatusEvent(order.ID, order.Status, order.Status, reason, actor)
+	event.RefundID = &refund.ID
+	return event
+}
+
+func reviewReason(reason, note string) string {
+	if note == "" {
+		return reason
+	}
+	return reason + ": " + note
+}
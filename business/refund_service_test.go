@@ -0,0 +1,140 @@
+package business
+
+import (
+	"testing"
+	"tumdum_backend/config"
+	"tumdum_backend/models"
+)
+
+// refundTestOrder is an order of two pizzas and a salad with a 10% discount,
+// tax, fees and a tip
+func refundTestOrder(status models.OrderStatus) *models.Order {
+	order := &models.Order{
+		ID:             1,
+		Status:         status,
+		SubtotalAmount: models.NewMoney(0, "USD"),
+		TaxRate:        8,
+		DeliveryFee:    models.NewMoney(299, "USD"),
+		PackagingFee:   models.NewMoney(50, "USD"),
+		TipAmount:      models.NewMoney(200, "USD"),
+		OrderItems: []models.OrderItem{
+			{ID: 1, DishID: 10, Quantity: 2, Price: models.NewMoney(1200, "USD"), LineTotal: models.NewMoney(2400, "USD")},
+			{ID: 2, DishID: 11, Quantity: 1, Price: models.NewMoney(800, "USD"), LineTotal: models.NewMoney(800, "USD")},
+		},
+		Discounts: []models.OrderDiscount{
+			{DiscountType: models.DiscountTypePercentage, PercentOff: 10},
+		},
+	}
+	NewOrderPricer(config.PricingConfig{}).Total(order)
+	return order
+}
+
+func TestRefundItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []models.RefundItemRequest
+		want      int64
+		wantErr   bool
+	}{
+		{name: "everything left", want: 3200},
+		{name: "one of two pizzas", requested: []models.RefundItemRequest{{OrderItemID: 1, Quantity: 1}}, want: 1200},
+		{name: "several items", requested: []models.RefundItemRequest{{OrderItemID: 1, Quantity: 2}, {OrderItemID: 2, Quantity: 1}}, want: 3200},
+		{name: "unknown item", requested: []models.RefundItemRequest{{OrderItemID: 9, Quantity: 1}}, wantErr: true},
+		{name: "item listed twice", requested: []models.RefundItemRequest{{OrderItemID: 1, Quantity: 1}, {OrderItemID: 1, Quantity: 1}}, wantErr: true},
+		{name: "zero quantity", requested: []models.RefundItemRequest{{OrderItemID: 1, Quantity: 0}}, wantErr: true},
+		{name: "more than ordered", requested: []models.RefundItemRequest{{OrderItemID: 2, Quantity: 2}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, amount, err := refundItems(refundTestOrder(models.OrderStatusPending), tt.requested)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if amount.Amount != tt.want {
+				t.Errorf("amount = %d, want %d", amount.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefundItemsNothingLeft(t *testing.T) {
+	order := refundTestOrder(models.OrderStatusDelivered)
+	for i := range order.OrderItems {
+		order.OrderItems[i].RefundedQuantity = order.OrderItems[i].Quantity
+	}
+	if _, _, err := refundItems(order, nil); err == nil {
+		t.Fatal("expected an error for an order with nothing left")
+	}
+}
+
+func TestRefundOrderItemsFullRefund(t *testing.T) {
+	for _, status := range []models.OrderStatus{models.OrderStatusPending, models.OrderStatusDelivered} {
+		t.Run(string(status), func(t *testing.T) {
+			order := refundTestOrder(status)
+			previousTotal := order.TotalAmount
+			items, _, err := refundItems(order, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			refund := &models.Refund{Items: items}
+
+			quantities, returned, remaining, err := refundOrderItems(order, refund, NewOrderPricer(config.PricingConfig{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if remaining != 0 {
+				t.Errorf("remaining = %d, want 0", remaining)
+			}
+			if len(quantities) != 2 || quantities[1] != 2 || quantities[2] != 1 {
+				t.Errorf("quantities = %v", quantities)
+			}
+			if len(returned) != 2 {
+				t.Errorf("returned %d items, want 2", len(returned))
+			}
+			// The order keeps a zero total, which the orders_total_amount_check allows
+			if order.TotalAmount.Amount != 0 {
+				t.Errorf("total = %d, want 0", order.TotalAmount.Amount)
+			}
+			if refund.Amount != previousTotal {
+				t.Errorf("refund amount = %v, want the previous total %v", refund.Amount, previousTotal)
+			}
+		})
+	}
+}
+
+func TestRefundOrderItemsPartialRefund(t *testing.T) {
+	order := refundTestOrder(models.OrderStatusDelivered)
+	previousTotal := order.TotalAmount.Amount
+	refund := &models.Refund{Items: []models.RefundItem{{OrderItemID: 2, Quantity: 1}}}
+
+	_, _, remaining, err := refundOrderItems(order, refund, NewOrderPricer(config.PricingConfig{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if remaining != 2 {
+		t.Errorf("remaining = %d, want 2", remaining)
+	}
+	// The discounted subtotal drops from 2880 to 2160 and the tax, rounded on
+	// the whole order, from 230 to 173
+	if want := int64(720 + 57); refund.Amount.Amount != want {
+		t.Errorf("refund amount = %d, want %d", refund.Amount.Amount, want)
+	}
+	if order.TotalAmount.Amount != previousTotal-refund.Amount.Amount {
+		t.Errorf("total = %d, want %d", order.TotalAmount.Amount, previousTotal-refund.Amount.Amount)
+	}
+}
+
+func TestRefundOrderItemsExceedsRemaining(t *testing.T) {
+	order := refundTestOrder(models.OrderStatusDelivered)
+	order.OrderItems[1].RefundedQuantity = 1
+	refund := &models.Refund{Items: []models.RefundItem{{OrderItemID: 2, Quantity: 1}}}
+	if _, _, _, err := refundOrderItems(order, refund, NewOrderPricer(config.PricingConfig{})); err == nil {
+		t.Fatal("expected an error")
+	}
+}
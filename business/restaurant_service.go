@@ -2,19 +2,35 @@ package business
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 	"tumdum_backend/dao"
 	"tumdum_backend/models"
 )
 
+// maxNearbyRadiusKm bounds how far nearby searches may reach
+const maxNearbyRadiusKm = 50.0
+
 type RestaurantService struct {
+	uow           *dao.UnitOfWork
 	restaurantDAO *dao.RestaurantDAO
+	memberDAO     *dao.RestaurantMemberDAO
+	userDAO       *dao.UserDAO
+	scheduleDAO   *dao.ScheduleDAO
 }
 
-func NewRestaurantService(restaurantDAO *dao.RestaurantDAO) *RestaurantService {
-	return &RestaurantService{restaurantDAO: restaurantDAO}
+func NewRestaurantService(uow *dao.UnitOfWork, restaurantDAO *dao.RestaurantDAO, memberDAO *dao.RestaurantMemberDAO, userDAO *dao.UserDAO, scheduleDAO *dao.ScheduleDAO) *RestaurantService {
+	return &RestaurantService{
+		uow:           uow,
+		restaurantDAO: restaurantDAO,
+		memberDAO:     memberDAO,
+		userDAO:       userDAO,
+		scheduleDAO:   scheduleDAO,
+	}
 }
 
-func (s *RestaurantService) CreateRestaurant(restaurant *models.Restaurant) error {
+func (s *RestaurantService) CreateRestaurant(restaurant *models.Restaurant, actor Actor) error {
 	// Validate required fields
 	if restaurant.Name == "" {
 		return errors.New("restaurant name is required")
@@ -44,23 +60,120 @@ func (s *RestaurantService) CreateRestaurant(restaurant *models.Restaurant) erro
 		return errors.New("restaurant cuisine is required")
 	}
 
-	// Validate rating
-	if restaurant.Rating < 0 || restaurant.Rating > 5 {
-		return errors.New("rating must be between 0 and 5")
+	// The rating is derived from reviews
+	restaurant.Rating = 0
+	restaurant.ReviewCount = 0
+	if restaurant.SlotCapacity < 0 {
+		return errors.New("slot capacity cannot be negative")
+	}
+
+	if err := normalizeCurrency(restaurant); err != nil {
+		return err
+	}
+	if err := validateRestaurantFees(restaurant); err != nil {
+		return err
+	}
+	if err := validateRestaurantLocation(restaurant); err != nil {
+		return err
+	}
+	if err := normalizeTimeZone(restaurant); err != nil {
+		return err
 	}
 
-	return s.restaurantDAO.Create(restaurant)
+	// Opening hours and members are managed through their own endpoints, and dishes
+	// and orders are never created along with a restaurant
+	clearRestaurantAssociations(restaurant)
+
+	// The creator becomes the restaurant's first owner
+	if err := s.restaurantDAO.CreateWithOwner(restaurant, actor.UserID); err != nil {
+		return err
+	}
+	// A new restaurant has no opening hours or closures yet
+	restaurant.IsOpenNow = restaurant.IsActive
+	return nil
+}
+
+// clearRestaurantAssociations drops the associations a restaurant bound from a
+// request may carry, so saving it cannot add members, dishes or orders
+func clearRestaurantAssociations(restaurant *models.Restaurant) {
+	restaurant.Dishes = nil
+	restaurant.Orders = nil
+	restaurant.Members = nil
+	restaurant.OpeningHours = nil
 }
 
 func (s *RestaurantService) GetRestaurantByID(id uint) (*models.Restaurant, error) {
-	return s.restaurantDAO.GetByID(id)
+	restaurant, err := s.restaurantDAO.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if restaurant.IsOpenNow, err = restaurantOpenAt(s.scheduleDAO, restaurant, time.Now()); err != nil {
+		return nil, err
+	}
+	return restaurant, nil
 }
 
-func (s *RestaurantService) GetAllRestaurants(cuisine, isActive, city string) ([]models.Restaurant, error) {
-	return s.restaurantDAO.GetAll(cuisine, isActive, city)
+func (s *RestaurantService) GetAllRestaurants(filter models.RestaurantFilter, page models.PageRequest) (*models.Page[models.Restaurant], error) {
+	restaurants, err := s.restaurantDAO.GetAll(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	if err := setOpenNow(s.scheduleDAO, restaurants.Data); err != nil {
+		return nil, err
+	}
+	return restaurants, nil
+}
+
+// GetNearbyRestaurants returns the active restaurants within radiusKm of the point, nearest first
+func (s *RestaurantService) GetNearbyRestaurants(lat, lng, radiusKm float64, limit int) ([]models.Restaurant, error) {
+	if !models.ValidCoordinates(lat, lng) {
+		return nil, errors.New("invalid coordinates")
+	}
+	if radiusKm <= 0 {
+		radiusKm = models.DefaultDeliveryRadiusKm
+	}
+	if radiusKm > maxNearbyRadiusKm {
+		return nil, fmt.Errorf("radius cannot exceed %g km", maxNearbyRadiusKm)
+	}
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	restaurants, err := s.restaurantDAO.GetNearby(lat, lng, radiusKm, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := setOpenNow(s.scheduleDAO, restaurants); err != nil {
+		return nil, err
+	}
+	return restaurants, nil
 }
 
-func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant) error {
+func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurant.ID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return err
+	}
+
+	existing, err := s.restaurantDAO.GetByID(restaurant.ID)
+	if err != nil {
+		return errors.New("restaurant not found")
+	}
+	// Dishes and orders are priced in the restaurant currency, so it cannot change
+	if restaurant.Currency == "" {
+		restaurant.Currency = existing.Currency
+	}
+	if !strings.EqualFold(restaurant.Currency, existing.Currency) {
+		return errors.New("restaurant currency cannot be changed")
+	}
+	if restaurant.DeliveryRadiusKm == 0 {
+		restaurant.DeliveryRadiusKm = existing.DeliveryRadiusKm
+	}
+	if restaurant.TimeZone == "" {
+		restaurant.TimeZone = existing.TimeZone
+	}
+
 	// Validate required fields
 	if restaurant.Name == "" {
 		return errors.New("restaurant name is required")
@@ -90,14 +203,230 @@ func (s *RestaurantService) UpdateRestaurant(restaurant *models.Restaurant) erro
 		return errors.New("restaurant cuisine is required")
 	}
 
-	// Validate rating
-	if restaurant.Rating < 0 || restaurant.Rating > 5 {
-		return errors.New("rating must be between 0 and 5")
+	// The rating is derived from reviews
+	restaurant.Rating = existing.Rating
+	restaurant.ReviewCount = existing.ReviewCount
+	if restaurant.SlotCapacity < 0 {
+		return errors.New("slot capacity cannot be negative")
+	}
+
+	if err := normalizeCurrency(restaurant); err != nil {
+		return err
+	}
+	if err := validateRestaurantFees(restaurant); err != nil {
+		return err
+	}
+	if err := validateRestaurantLocation(restaurant); err != nil {
+		return err
+	}
+	if err := normalizeTimeZone(restaurant); err != nil {
+		return err
 	}
 
-	return s.restaurantDAO.Update(restaurant)
+	clearRestaurantAssociations(restaurant)
+	if err := s.restaurantDAO.Update(restaurant); err != nil {
+		return err
+	}
+	restaurant.IsOpenNow, err = restaurantOpenAt(s.scheduleDAO, restaurant, time.Now())
+	return err
 }
 
 func (s *RestaurantService) DeleteRestaurant(id uint) error {
 	return s.restaurantDAO.Delete(id)
 }
+
+// GetSchedule returns the weekly opening hours and upcoming closures of a restaurant
+func (s *RestaurantService) GetSchedule(restaurantID uint) (*models.RestaurantSchedule, error) {
+	restaurant, err := s.restaurantDAO.GetByID(restaurantID)
+	if err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+
+	now := time.Now()
+	hours, err := s.scheduleDAO.GetOpeningHours(restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	closures, err := s.scheduleDAO.GetClosures(now, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RestaurantSchedule{
+		RestaurantID: restaurant.ID,
+		TimeZone:     restaurant.TimeZone,
+		IsOpenNow:    isOpenAt(restaurant, hours, closures, now),
+		OpeningHours: hours,
+		Closures:     closures,
+	}, nil
+}
+
+// UpdateOpeningHours replaces the weekly opening hours of a restaurant. An empty
+// list removes the schedule, leaving the restaurant open whenever it is active.
+func (s *RestaurantService) UpdateOpeningHours(restaurantID uint, hours []models.OpeningHours, actor Actor) (*models.RestaurantSchedule, error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return nil, err
+	}
+	if _, err := s.restaurantDAO.GetByID(restaurantID); err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+	if err := validateOpeningHours(hours); err != nil {
+		return nil, err
+	}
+
+	for i := range hours {
+		hours[i].ID = 0
+		hours[i].RestaurantID = restaurantID
+	}
+	if err := s.scheduleDAO.ReplaceOpeningHours(restaurantID, hours); err != nil {
+		return nil, err
+	}
+	return s.GetSchedule(restaurantID)
+}
+
+// AddClosure closes a restaurant for a holiday or other exception
+func (s *RestaurantService) AddClosure(restaurantID uint, closure *models.RestaurantClosure, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return err
+	}
+	if _, err := s.restaurantDAO.GetByID(restaurantID); err != nil {
+		return errors.New("restaurant not found")
+	}
+	if !closure.EndsAt.After(closure.StartsAt) {
+		return errors.New("closure must end after it starts")
+	}
+
+	closure.ID = 0
+	closure.RestaurantID = restaurantID
+	return s.scheduleDAO.CreateClosure(closure)
+}
+
+func (s *RestaurantService) RemoveClosure(restaurantID, closureID uint, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return err
+	}
+
+	closure, err := s.scheduleDAO.GetClosureByID(closureID)
+	if err != nil || closure.RestaurantID != restaurantID {
+		return errors.New("closure not found")
+	}
+	return s.scheduleDAO.DeleteClosure(closureID)
+}
+
+func (s *RestaurantService) AddMember(restaurantID uint, invite *models.RestaurantMemberInvite, actor Actor) (*models.RestaurantMember, error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner); err != nil {
+		return nil, err
+	}
+	if !invite.Role.IsValid() {
+		return nil, errors.New("invalid member role")
+	}
+
+	if _, err := s.restaurantDAO.GetByID(restaurantID); err != nil {
+		return nil, errors.New("restaurant not found")
+	}
+
+	user, err := s.userDAO.GetByEmail(invite.Email)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if existing, err := s.memberDAO.GetByRestaurantAndUser(restaurantID, user.ID); err == nil && existing != nil {
+		return nil, errors.New("user is already a member of this restaurant")
+	}
+
+	invitedByID := actor.UserID
+	member := &models.RestaurantMember{
+		RestaurantID: restaurantID,
+		UserID:       user.ID,
+		User:         *user,
+		Role:         invite.Role,
+		InvitedByID:  &invitedByID,
+	}
+	if err := s.memberDAO.Create(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+func (s *RestaurantService) GetMembers(restaurantID uint, actor Actor) ([]models.RestaurantMember, error) {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID); err != nil {
+		return nil, err
+	}
+	return s.memberDAO.GetByRestaurantID(restaurantID)
+}
+
+func (s *RestaurantService) RemoveMember(restaurantID, memberID uint, actor Actor) error {
+	if err := checkRestaurantMember(s.memberDAO, actor, restaurantID, models.MemberRoleOwner); err != nil {
+		return err
+	}
+
+	return s.uow.Do(func(tx *dao.Tx) error {
+		// Locking the restaurant serializes removals so two of them cannot take
+		// away the last two owners
+		if _, err := tx.Restaurants.GetByIDForUpdate(restaurantID); err != nil {
+			return errors.New("restaurant not found")
+		}
+		member, err := tx.Members.GetByID(memberID)
+		if err != nil || member.RestaurantID != restaurantID {
+			return errors.New("member not found")
+		}
+
+		// A restaurant must always keep at least one owner
+		if member.Role == models.MemberRoleOwner {
+			owners, err := tx.Members.CountByRole(restaurantID, models.MemberRoleOwner)
+			if err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return errors.New("cannot remove the last owner of a restaurant")
+			}
+		}
+
+		return tx.Members.Delete(memberID)
+	})
+}
+
+// normalizeCurrency defaults the restaurant currency and validates its ISO 4217 code
+func normalizeCurrency(restaurant *models.Restaurant) error {
+	if restaurant.Currency == "" {
+		restaurant.Currency = models.DefaultCurrency
+	}
+	restaurant.Currency = strings.ToUpper(restaurant.Currency)
+	if len(restaurant.Currency) != 3 {
+		return errors.New("restaurant currency must be a 3-letter ISO 4217 code")
+	}
+	return nil
+}
+
+// validateRestaurantFees defaults the currency of the restaurant's fees to the
+// restaurant currency and checks they are not negative
+func validateRestaurantFees(restaurant *models.Restaurant) error {
+	for _, fee := range []*models.Money{&restaurant.DeliveryFee, &restaurant.DeliveryFeePerKm, &restaurant.PackagingFee} {
+		fee.Currency = strings.ToUpper(strings.TrimSpace(fee.Currency))
+		if fee.Currency == "" {
+			fee.Currency = restaurant.Currency
+		}
+		if fee.Currency != restaurant.Currency {
+			return errors.New("restaurant fees must be in the restaurant currency")
+		}
+		if fee.Amount < 0 {
+			return errors.New("restaurant fees cannot be negative")
+		}
+	}
+	return nil
+}
+
+// validateRestaurantLocation checks the restaurant coordinates and delivery radius,
+// applying the default radius when none is set
+func validateRestaurantLocation(restaurant *models.Restaurant) error {
+	if err := validateLocation(restaurant.Latitude, restaurant.Longitude); err != nil {
+		return err
+	}
+	if restaurant.DeliveryRadiusKm == 0 {
+		restaurant.DeliveryRadiusKm = models.DefaultDeliveryRadiusKm
+	}
+	if restaurant.DeliveryRadiusKm < 0 {
+		return errors.New("delivery radius must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package business
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// ReviewService manages customer reviews of delivered orders and keeps the
+// ratings of restaurants and dishes in line with them
+type ReviewService struct {
+	uow       *dao.UnitOfWork
+	reviewDAO *dao.ReviewDAO
+	orderDAO  *dao.OrderDAO
+	memberDAO *dao.RestaurantMemberDAO
+}
+
+func NewReviewService(uow *dao.UnitOfWork, reviewDAO *dao.ReviewDAO, orderDAO *dao.OrderDAO, memberDAO *dao.RestaurantMemberDAO) *ReviewService {
+	return &ReviewService{
+		uow:       uow,
+		reviewDAO: reviewDAO,
+		orderDAO:  orderDAO,
+		memberDAO: memberDAO,
+	}
+}
+
+// CreateReview reviews a delivered order of the actor. Each order can be
+// reviewed once, and dish ratings must be for dishes of the order.
+func (s *ReviewService) CreateReview(orderID uint, request *models.ReviewRequest, actor Actor) (*models.Review, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil || order.UserID != actor.UserID {
+		return nil, errors.New("order not found")
+	}
+	if order.Status != models.OrderStatusDelivered {
+		return nil, errors.New("only delivered orders can be reviewed")
+	}
+
+	ordered := make(map[uint]bool, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		ordered[item.DishID] = true
+	}
+	review := &models.Review{
+		OrderID:      order.ID,
+		UserID:       actor.UserID,
+		RestaurantID: order.RestaurantID,
+		Rating:       request.Rating,
+		Comment:      strings.TrimSpace(request.Comment),
+		Status:       models.ReviewStatusPublished,
+	}
+	rated := make(map[uint]bool, len(request.DishRatings))
+	for _, dishRating := range request.DishRatings {
+		if !ordered[dishRating.DishID] {
+			return nil, errors.New("dish was not part of the order")
+		}
+		if rated[dishRating.DishID] {
+			return nil, errors.New("dish is rated more than once")
+		}
+		rated[dishRating.DishID] = true
+		review.DishRatings = append(review.DishRatings, models.DishRating{DishID: dishRating.DishID, Rating: dishRating.Rating})
+	}
+
+	err = s.uow.Do(func(tx *dao.Tx) error {
+		// Ratings of the restaurant are worked out one review at a time
+		if _, err := tx.Restaurants.GetByIDForUpdate(order.RestaurantID); err != nil {
+			return errors.New("restaurant not found")
+		}
+		reviews, err := tx.Reviews.CountByOrderID(order.ID)
+		if err != nil {
+			return err
+		}
+		if reviews > 0 {
+			return errors.New("order has already been reviewed")
+		}
+		if err := tx.Reviews.Create(review); err != nil {
+			return err
+		}
+		return tx.Reviews.RefreshRatings(order.RestaurantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reviewDAO.GetByID(review.ID)
+}
+
+// GetOrderReview returns the review of an order to anyone who may view the order
+func (s *ReviewService) GetOrderReview(orderID uint, actor Actor) (*models.Review, error) {
+	order, err := s.orderDAO.GetByID(orderID)
+	if err != nil {
+		return nil, errors.New("order not found")
+	}
+	if order.UserID != actor.UserID && !isOrderDriver(order, actor) &&
+		checkRestaurantMember(s.memberDAO, actor, order.RestaurantID) != nil {
+		return nil, ErrForbidden
+	}
+	review, err := s.reviewDAO.GetByOrderID(orderID)
+	if err != nil {
+		return nil, errors.New("review not found")
+	}
+	if !actor.IsAdmin() {
+		hideModeration(review)
+	}
+	return review, nil
+}
+
+// GetRestaurantReviews returns a page of the reviews of a restaurant that are not hidden
+func (s *ReviewService) GetRestaurantReviews(restaurantID uint, page models.PageRequest) (*models.Page[models.Review], error) {
+	filter := models.ReviewFilter{
+		RestaurantID: restaurantID,
+		Statuses:     []models.ReviewStatus{models.ReviewStatusPublished, models.ReviewStatusFlagged},
+	}
+	reviews, err := s.reviewDAO.GetAll(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reviews.Data {
+		hideModeration(&reviews.Data[i])
+	}
+	return reviews, nil
+}
+
+// GetReviews returns a page of reviews in any status, for moderation
+func (s *ReviewService) GetReviews(filter models.ReviewFilter, page models.PageRequest) (*models.Page[models.Review], error) {
+	for _, status := range filter.Statuses {
+		if !status.IsValid() {
+			return nil, errors.New("invalid review status")
+		}
+	}
+	return s.reviewDAO.GetAll(filter, page)
+}
+
+// ReplyToReview sets the restaurant's answer to a review. Only owners and
+// managers of the restaurant may answer; an empty reply removes the answer.
+func (s *ReviewService) ReplyToReview(reviewID uint, reply string, actor Actor) (*models.Review, error) {
+	review, err := s.reviewDAO.GetByID(reviewID)
+	if err != nil {
+		return nil, errors.New("review not found")
+	}
+	if err := checkRestaurantMember(s.memberDAO, actor, review.RestaurantID, models.MemberRoleOwner, models.MemberRoleManager); err != nil {
+		return nil, err
+	}
+
+	err = s.update(reviewID, func(review *models.Review) error {
+		review.Reply = strings.TrimSpace(reply)
+		review.RepliedBy = nil
+		review.RepliedAt = nil
+		if review.Reply != "" {
+			now := time.Now()
+			review.RepliedBy = &actor.UserID
+			review.RepliedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	review, err = s.reviewDAO.GetByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() {
+		hideModeration(review)
+	}
+	return review, nil
+}
+
+// FlagReview reports a published review to the moderators. It stays visible and
+// keeps counting towards ratings until an admin hides it.
+func (s *ReviewService) FlagReview(reviewID uint, reason string, actor Actor) (*models.Review, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+	err := s.update(reviewID, func(review *models.Review) error {
+		if review.UserID == actor.UserID {
+			return errors.New("cannot flag your own review")
+		}
+		if review.Status != models.ReviewStatusPublished {
+			return errors.New("review has already been flagged or hidden")
+		}
+		review.Status = models.ReviewStatusFlagged
+		review.FlaggedBy = &actor.UserID
+		review.FlagReason = reason
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	review, err := s.reviewDAO.GetByID(reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.IsAdmin() {
+		hideModeration(review)
+	}
+	return review, nil
+}
+
+// ModerateReview publishes or hides a review and works out the ratings of its
+// restaurant again. Only admins moderate reviews.
+func (s *ReviewService) ModerateReview(reviewID uint, moderation *models.ReviewModeration, actor Actor) (*models.Review, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if moderation.Status != models.ReviewStatusPublished && moderation.Status != models.ReviewStatusHidden {
+		return nil, errors.New("status must be PUBLISHED or HIDDEN")
+	}
+
+	err := s.uow.Do(func(tx *dao.Tx) error {
+		review, err := tx.Reviews.GetByIDForUpdate(reviewID)
+		if err != nil {
+			return errors.New("review not found")
+		}
+		if _, err := tx.Restaurants.GetByIDForUpdate(review.RestaurantID); err != nil {
+			return errors.New("restaurant not found")
+		}
+		now := time.Now()
+		review.Status = moderation.Status
+		review.ModeratedBy = &actor.UserID
+		review.ModeratedAt = &now
+		review.ModerationNote = strings.TrimSpace(moderation.Note)
+		if err := tx.Reviews.Update(review); err != nil {
+			return err
+		}
+		return tx.Reviews.RefreshRatings(review.RestaurantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reviewDAO.GetByID(reviewID)
+}
+
+// hideModeration clears who flagged or moderated a review and why, which only
+// admins get to see
+func hideModeration(review *models.Review) {
+	review.FlaggedBy = nil
+	review.FlagReason = ""
+	review.ModeratedBy = nil
+	review.ModeratedAt = nil
+	review.ModerationNote = ""
+}
+
+// update runs fn on the locked review and saves it
+func (s *ReviewService) update(reviewID uint, fn func(review *models.Review) error) error {
+	return s.uow.Do(func(tx *dao.Tx) error {
+		review, err := tx.Reviews.GetByIDForUpdate(reviewID)
+		if err != nil {
+			return errors.New("review not found")
+		}
+		if err := fn(review); err != nil {
+			return err
+		}
+		return tx.Reviews.Update(review)
+	})
+}
@@ -0,0 +1,148 @@
+package business
+
+import (
+	"errors"
+	"time"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+)
+
+// normalizeTimeZone checks that the restaurant time zone is a known IANA zone,
+// defaulting to UTC
+func normalizeTimeZone(restaurant *models.Restaurant) error {
+	if restaurant.TimeZone == "" {
+		restaurant.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(restaurant.TimeZone); err != nil {
+		return errors.New("invalid time zone")
+	}
+	return nil
+}
+
+// restaurantLocation returns the time zone of the restaurant, falling back to UTC
+func restaurantLocation(restaurant *models.Restaurant) *time.Location {
+	loc, err := time.LoadLocation(restaurant.TimeZone)
+	if err != nil || restaurant.TimeZone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// validateOpeningHours checks every interval and rewrites its times as HH:MM:SS
+func validateOpeningHours(hours []models.OpeningHours) error {
+	for i := range hours {
+		if hours[i].DayOfWeek < 0 || hours[i].DayOfWeek > 6 {
+			return errors.New("day_of_week must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		opens, err := models.ParseClock(hours[i].OpensAt)
+		if err != nil {
+			return err
+		}
+		closes, err := models.ParseClock(hours[i].ClosesAt)
+		if err != nil {
+			return err
+		}
+		hours[i].OpensAt = models.FormatClock(opens)
+		hours[i].ClosesAt = models.FormatClock(closes)
+	}
+	return nil
+}
+
+// isOpenAt reports whether the restaurant accepts orders at t. Active restaurants
+// without any opening hours are always open, except during closures.
+func isOpenAt(restaurant *models.Restaurant, hours []models.OpeningHours, closures []models.RestaurantClosure, t time.Time) bool {
+	if !restaurant.IsActive {
+		return false
+	}
+	for _, closure := range closures {
+		if !t.Before(closure.StartsAt) && t.Before(closure.EndsAt) {
+			return false
+		}
+	}
+	if len(hours) == 0 {
+		return true
+	}
+
+	local := t.In(restaurantLocation(restaurant))
+	day := int(local.Weekday())
+	previousDay := (day + 6) % 7
+	sinceMidnight := time.Duration(local.Hour())*time.Hour +
+		time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+
+	for _, interval := range hours {
+		opens, err := models.ParseClock(interval.OpensAt)
+		if err != nil {
+			continue
+		}
+		closes, err := models.ParseClock(interval.ClosesAt)
+		if err != nil {
+			continue
+		}
+
+		if opens < closes {
+			if interval.DayOfWeek == day && sinceMidnight >= opens && sinceMidnight < closes {
+				return true
+			}
+			continue
+		}
+		// The interval runs past midnight
+		if interval.DayOfWeek == day && sinceMidnight >= opens {
+			return true
+		}
+		if interval.DayOfWeek == previousDay && sinceMidnight < closes {
+			return true
+		}
+	}
+	return false
+}
+
+// restaurantOpenAt loads the schedule of the restaurant and reports whether it is open at t
+func restaurantOpenAt(scheduleDAO *dao.ScheduleDAO, restaurant *models.Restaurant, t time.Time) (bool, error) {
+	hours, err := scheduleDAO.GetOpeningHours(restaurant.ID)
+	if err != nil {
+		return false, err
+	}
+	closures, err := scheduleDAO.GetClosures(t, restaurant.ID)
+	if err != nil {
+		return false, err
+	}
+	return isOpenAt(restaurant, hours, closures, t), nil
+}
+
+// setOpenNow computes IsOpenNow for each restaurant with a single query for the
+// opening hours and closures of all of them
+func setOpenNow(scheduleDAO *dao.ScheduleDAO, restaurants []models.Restaurant) error {
+	if len(restaurants) == 0 {
+		return nil
+	}
+	now := time.Now()
+
+	ids := make([]uint, 0, len(restaurants))
+	for _, restaurant := range restaurants {
+		ids = append(ids, restaurant.ID)
+	}
+	hours, err := scheduleDAO.GetOpeningHours(ids...)
+	if err != nil {
+		return err
+	}
+	closures, err := scheduleDAO.GetClosures(now, ids...)
+	if err != nil {
+		return err
+	}
+
+	hoursByRestaurant := make(map[uint][]models.OpeningHours)
+	for _, interval := range hours {
+		hoursByRestaurant[interval.RestaurantID] = append(hoursByRestaurant[interval.RestaurantID], interval)
+	}
+	closuresByRestaurant := make(map[uint][]models.RestaurantClosure)
+	for _, closure := range closures {
+		closuresByRestaurant[closure.RestaurantID] = append(closuresByRestaurant[closure.RestaurantID], closure)
+	}
+
+	for i := range restaurants {
+		id := restaurants[i].ID
+		restaurants[i].IsOpenNow = isOpenAt(&restaurants[i], hoursByRestaurant[id], closuresByRestaurant[id], now)
+	}
+	return nil
+}
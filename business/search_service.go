@@ -0,0 +1,95 @@
+package business
+
+import (
+	"errors"
+	"strings"
+	"tumdum_backend/dao"
+	"tumdum_backend/models"
+	"unicode"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+	minSearchLength    = 2
+)
+
+// Search types accepted by SearchService.Search
+const (
+	SearchTypeAll         = "all"
+	SearchTypeRestaurants = "restaurants"
+	SearchTypeDishes      = "dishes"
+)
+
+type SearchService struct {
+	searchDAO *dao.SearchDAO
+}
+
+func NewSearchService(searchDAO *dao.SearchDAO) *SearchService {
+	return &SearchService{searchDAO: searchDAO}
+}
+
+// Search finds restaurants and dishes matching the query. Every word is matched
+// as a prefix, so "pan tik" finds "Paneer Tikka".
+func (s *SearchService) Search(query, searchType string, limit int) (*models.SearchResults, error) {
+	query = strings.TrimSpace(query)
+	if len([]rune(query)) < minSearchLength {
+		return nil, errors.New("search query must be at least 2 characters")
+	}
+
+	if searchType == "" {
+		searchType = SearchTypeAll
+	}
+	if searchType != SearchTypeAll && searchType != SearchTypeRestaurants && searchType != SearchTypeDishes {
+		return nil, errors.New("invalid search type")
+	}
+
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	results := &models.SearchResults{
+		Query:       query,
+		Restaurants: []models.RestaurantSearchResult{},
+		Dishes:      []models.DishSearchResult{},
+	}
+
+	tsQuery := buildPrefixQuery(query)
+	if tsQuery == "" {
+		return results, nil
+	}
+
+	if searchType != SearchTypeDishes {
+		restaurants, err := s.searchDAO.SearchRestaurants(query, tsQuery, limit)
+		if err != nil {
+			return nil, err
+		}
+		results.Restaurants = append(results.Restaurants, restaurants...)
+	}
+	if searchType != SearchTypeRestaurants {
+		dishes, err := s.searchDAO.SearchDishes(query, tsQuery, limit)
+		if err != nil {
+			return nil, err
+		}
+		results.Dishes = append(results.Dishes, dishes...)
+	}
+
+	return results, nil
+}
+
+// buildPrefixQuery turns free text into a tsquery requiring every word as a
+// prefix, e.g. "paneer tikka" becomes "paneer:* & tikka:*". Anything other than
+// letters and digits is dropped so user input cannot inject tsquery operators.
+func buildPrefixQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
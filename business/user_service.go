@@ -18,6 +18,14 @@ func NewUserService(userDAO *dao.UserDAO) *UserService {
 }
 
 func (s *UserService) CreateUser(user *models.User) error {
+	// Self-registered users are always customers
+	if user.Role == "" {
+		user.Role = models.UserRoleCustomer
+	}
+	if err := validateLocation(user.Latitude, user.Longitude); err != nil {
+		return err
+	}
+
 	err := s.userDAO.Create(user)
 	if err != nil {
 		if strings.Contains(err.Error(), "users_email_unique") {
@@ -57,11 +65,37 @@ func (s *UserService) UpdateUser(id string, user *models.User) error {
 	user.Email = existingUser.Email
 	// Don't allow updating password through this method
 	user.Password = existingUser.Password
+	// Don't allow updating role through this method
+	user.Role = existingUser.Role
 	user.ID = uint(userID)
 
+	if err := validateLocation(user.Latitude, user.Longitude); err != nil {
+		return err
+	}
+
 	return s.userDAO.Update(user)
 }
 
+func (s *UserService) UpdateUserRole(id string, role models.UserRole) (*models.User, error) {
+	userID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+	if !role.IsValid() {
+		return nil, errors.New("invalid role")
+	}
+	user, err := s.userDAO.GetByID(uint(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	if err := s.userDAO.Update(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (s *UserService) DeleteUser(id string) error {
 	userID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
@@ -3,25 +3,32 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	Server   ServerConfig   `yaml:"server"`
-	JWT      JWTConfig      `yaml:"jwt"`
-	API      APIConfig      `yaml:"api"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Server     ServerConfig     `yaml:"server"`
+	JWT        JWTConfig        `yaml:"jwt"`
+	API        APIConfig        `yaml:"api"`
+	Scheduling SchedulingConfig `yaml:"scheduling"`
+	Payments   PaymentsConfig   `yaml:"payments"`
+	Pricing    PricingConfig    `yaml:"pricing"`
+	Delivery   DeliveryConfig   `yaml:"delivery"`
+	Estimates  EstimatesConfig  `yaml:"estimates"`
 }
 
 type DatabaseConfig struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	DBName   string `yaml:"name"`
-	SSLMode  string `yaml:"sslmode"`
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"`
+	User        string `yaml:"user"`
+	Password    string `yaml:"password"`
+	DBName      string `yaml:"name"`
+	SSLMode     string `yaml:"sslmode"`
+	AutoMigrate bool   `yaml:"auto_migrate"`
 }
 
 type ServerConfig struct {
@@ -36,6 +43,119 @@ type APIConfig struct {
 	UnsplashAccessKey string `yaml:"unsplash_access_key"`
 }
 
+// SchedulingConfig controls pre-orders for a future delivery slot
+type SchedulingConfig struct {
+	SlotMinutes        int `yaml:"slot_minutes"`         // length of a delivery slot
+	MinLeadMinutes     int `yaml:"min_lead_minutes"`     // earliest a slot can start after ordering
+	MaxDaysAhead       int `yaml:"max_days_ahead"`       // how far ahead slots can be booked
+	ReleaseLeadMinutes int `yaml:"release_lead_minutes"` // how long before the slot an order reaches the kitchen
+	PollSeconds        int `yaml:"poll_seconds"`         // how often scheduled orders are checked for release
+}
+
+// WithDefaults fills in unset scheduling options
+func (c SchedulingConfig) WithDefaults() SchedulingConfig {
+	if c.SlotMinutes <= 0 {
+		c.SlotMinutes = 15
+	}
+	if c.MinLeadMinutes <= 0 {
+		c.MinLeadMinutes = 60
+	}
+	if c.MaxDaysAhead <= 0 {
+		c.MaxDaysAhead = 7
+	}
+	if c.ReleaseLeadMinutes <= 0 {
+		c.ReleaseLeadMinutes = 45
+	}
+	if c.PollSeconds <= 0 {
+		c.PollSeconds = 30
+	}
+	return c
+}
+
+// PaymentsConfig selects the payment provider
+type PaymentsConfig struct {
+	Provider      string `yaml:"provider"`       // "mock" (default) runs an in-process fake provider
+	WebhookSecret string `yaml:"webhook_secret"` // shared secret used to sign provider webhooks
+}
+
+// PricingConfig holds the sales tax rates applied to orders
+type PricingConfig struct {
+	// TaxRates maps a restaurant location to a tax rate in percent. Keys are
+	// "COUNTRY/STATE", "COUNTRY" or "default" and are matched case-insensitively
+	// against the restaurant's country and state, most specific first.
+	TaxRates map[string]float64 `yaml:"tax_rates"`
+}
+
+// TaxRate returns the tax rate in percent for a restaurant location
+func (c PricingConfig) TaxRate(country, state string) float64 {
+	rates := make(map[string]float64, len(c.TaxRates))
+	for key, rate := range c.TaxRates {
+		rates[strings.ToUpper(strings.TrimSpace(key))] = rate
+	}
+	country = strings.ToUpper(strings.TrimSpace(country))
+	state = strings.ToUpper(strings.TrimSpace(state))
+	for _, key := range []string{country + "/" + state, country, "DEFAULT"} {
+		if rate, ok := rates[key]; ok {
+			return rate
+		}
+	}
+	return 0
+}
+
+// DeliveryConfig controls how ready orders are offered to drivers
+type DeliveryConfig struct {
+	OfferRadiusKm         float64 `yaml:"offer_radius_km"`          // how far from the restaurant drivers are offered orders
+	OfferTimeoutSeconds   int     `yaml:"offer_timeout_seconds"`    // how long a driver has to accept an offer
+	LocationMaxAgeSeconds int     `yaml:"location_max_age_seconds"` // drivers whose last location is older are not offered orders
+	PollSeconds           int     `yaml:"poll_seconds"`             // how often offers expire and ready orders are offered
+}
+
+// WithDefaults fills in unset delivery options
+func (c DeliveryConfig) WithDefaults() DeliveryConfig {
+	if c.OfferRadiusKm <= 0 {
+		c.OfferRadiusKm = 5
+	}
+	if c.OfferTimeoutSeconds <= 0 {
+		c.OfferTimeoutSeconds = 60
+	}
+	if c.LocationMaxAgeSeconds <= 0 {
+		c.LocationMaxAgeSeconds = 300
+	}
+	if c.PollSeconds <= 0 {
+		c.PollSeconds = 10
+	}
+	return c
+}
+
+// EstimatesConfig controls how order ready and delivery times are estimated
+type EstimatesConfig struct {
+	DefaultPrepMinutes   int     `yaml:"default_prep_minutes"`    // prep time of dishes that do not set one
+	QueueMinutesPerOrder int     `yaml:"queue_minutes_per_order"` // added for each order already in the kitchen
+	HandoffMinutes       int     `yaml:"handoff_minutes"`         // from ready to on the way
+	SpeedKmh             float64 `yaml:"speed_kmh"`               // average delivery speed
+	DefaultTravelMinutes int     `yaml:"default_travel_minutes"`  // travel time of orders without a known distance
+}
+
+// WithDefaults fills in unset estimate options
+func (c EstimatesConfig) WithDefaults() EstimatesConfig {
+	if c.DefaultPrepMinutes <= 0 {
+		c.DefaultPrepMinutes = 10
+	}
+	if c.QueueMinutesPerOrder <= 0 {
+		c.QueueMinutesPerOrder = 5
+	}
+	if c.HandoffMinutes <= 0 {
+		c.HandoffMinutes = 5
+	}
+	if c.SpeedKmh <= 0 {
+		c.SpeedKmh = 20
+	}
+	if c.DefaultTravelMinutes <= 0 {
+		c.DefaultTravelMinutes = 15
+	}
+	return c
+}
+
 // LoadConfig loads configuration from environment variables
 func LoadConfig(path string) (*Config, error) {
 	config := &Config{}
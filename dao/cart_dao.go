@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartDAO struct {
+	db *gorm.DB
+}
+
+func NewCartDAO(db *gorm.DB) *CartDAO {
+	return &CartDAO{db: db}
+}
+
+// GetOrCreateByUserID returns the user's cart with its items, dishes and modifiers, creating
+// an empty cart on first use
+func (dao *CartDAO) GetOrCreateByUserID(userID uint) (*models.Cart, error) {
+	return dao.getOrCreate(userID, false)
+}
+
+// GetOrCreateByUserIDForUpdate is GetOrCreateByUserID that also locks the cart row
+// until the surrounding transaction ends
+func (dao *CartDAO) GetOrCreateByUserIDForUpdate(userID uint) (*models.Cart, error) {
+	return dao.getOrCreate(userID, true)
+}
+
+func (dao *CartDAO) getOrCreate(userID uint, lock bool) (*models.Cart, error) {
+	var cart models.Cart
+	err := dao.db.Where(models.Cart{UserID: userID}).FirstOrCreate(&cart).Error
+	if err != nil {
+		return nil, err
+	}
+	// The row is locked before the items are read so they cannot change underneath
+	if lock {
+		err = dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Cart{}, cart.ID).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = dao.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("cart_items.id")
+	}).Preload("Items.Dish").Preload("Items.Modifiers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("cart_item_modifiers.id")
+	}).Preload("Items.Modifiers.ModifierOption").First(&cart, cart.ID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// SetRestaurant records which restaurant the cart's dishes come from, nil for an empty cart
+func (dao *CartDAO) SetRestaurant(cartID uint, restaurantID *uint) error {
+	return dao.db.Model(&models.Cart{}).Where("id = ?", cartID).
+		Update("restaurant_id", restaurantID).Error
+}
+
+// CreateItem creates the cart item together with its modifiers
+func (dao *CartDAO) CreateItem(item *models.CartItem) error {
+	return dao.db.Omit("Dish").Create(item).Error
+}
+
+func (dao *CartDAO) UpdateItemQuantity(itemID uint, quantity int) error {
+	return dao.db.Model(&models.CartItem{}).Where("id = ?", itemID).
+		Update("quantity", quantity).Error
+}
+
+func (dao *CartDAO) DeleteItem(itemID uint) error {
+	return dao.db.Delete(&models.CartItem{}, itemID).Error
+}
+
+// Clear removes every item from the cart and detaches it from its restaurant
+func (dao *CartDAO) Clear(cartID uint) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Cart{}).Where("id = ?", cartID).
+			Update("restaurant_id", nil).Error
+	})
+}
@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"time"
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeliveryOfferDAO struct {
+	db *gorm.DB
+}
+
+func NewDeliveryOfferDAO(db *gorm.DB) *DeliveryOfferDAO {
+	return &DeliveryOfferDAO{db: db}
+}
+
+func (dao *DeliveryOfferDAO) Create(offer *models.DeliveryOffer) error {
+	return dao.db.Omit("Order").Create(offer).Error
+}
+
+func (dao *DeliveryOfferDAO) Update(offer *models.DeliveryOffer) error {
+	return dao.db.Omit("Order").Save(offer).Error
+}
+
+// GetByIDForUpdate loads the offer and locks its row until the surrounding transaction ends
+func (dao *DeliveryOfferDAO) GetByIDForUpdate(id uint) (*models.DeliveryOffer, error) {
+	var offer models.DeliveryOffer
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&offer, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// GetOpenByDriverID returns the offers awaiting the driver's answer with their
+// orders and restaurants
+func (dao *DeliveryOfferDAO) GetOpenByDriverID(driverID uint, now time.Time) ([]models.DeliveryOffer, error) {
+	var offers []models.DeliveryOffer
+	err := dao.db.Preload("Order.Restaurant").
+		Where("driver_id = ? AND status = ? AND expires_at > ?", driverID, models.DeliveryOfferStatusOffered, now).
+		Order("id").Find(&offers).Error
+	return offers, err
+}
+
+// ExpireBefore marks the offers that were not answered in time as expired
+func (dao *DeliveryOfferDAO) ExpireBefore(now time.Time) (int64, error) {
+	result := dao.db.Model(&models.DeliveryOffer{}).
+		Where("status = ? AND expires_at <= ?", models.DeliveryOfferStatusOffered, now).
+		Update("status", models.DeliveryOfferStatusExpired)
+	return result.RowsAffected, result.Error
+}
@@ -1,9 +1,12 @@
 package dao
 
 import (
+	"database/sql"
+	"time"
 	"tumdum_backend/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DishDAO struct {
@@ -20,21 +23,103 @@ func (dao *DishDAO) Create(dish *models.Dish) error {
 
 func (dao *DishDAO) GetByID(id uint) (*models.Dish, error) {
 	var dish models.Dish
-	err := dao.db.Preload("Restaurant").First(&dish, id).Error
+	err := preloadModifiers(dao.db.Preload("Restaurant"), "ModifierGroups").First(&dish, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &dish, nil
 }
 
-func (dao *DishDAO) GetByRestaurantID(restaurantID uint) ([]models.Dish, error) {
+// GetByIDsForUpdate loads the dishes and locks their rows until the surrounding
+// transaction ends. Rows are locked in ID order to avoid deadlocks.
+func (dao *DishDAO) GetByIDsForUpdate(ids []uint) ([]models.Dish, error) {
 	var dishes []models.Dish
-	err := dao.db.Where("restaurant_id = ?", restaurantID).Find(&dishes).Error
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).Order("id").Find(&dishes).Error
 	return dishes, err
 }
 
+// GetByIDs loads the dishes with the given IDs
+func (dao *DishDAO) GetByIDs(ids []uint) ([]models.Dish, error) {
+	var dishes []models.Dish
+	err := dao.db.Where("id IN ?", ids).Find(&dishes).Error
+	return dishes, err
+}
+
+var dishSortColumns = sortColumns{
+	"id":            "id",
+	"name":          "name",
+	"price":         "price_amount",
+	"category":      "category",
+	"display_order": "display_order",
+	"created_at":    "created_at",
+}
+
+func (dao *DishDAO) GetByRestaurantID(restaurantID uint, filter models.DishFilter, page models.PageRequest) (*models.Page[models.Dish], error) {
+	query := preloadModifiers(dao.db.Model(&models.Dish{}), "ModifierGroups").
+		Where("restaurant_id = ?", restaurantID)
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.MenuCategoryID != 0 {
+		query = query.Where("menu_category_id = ?", filter.MenuCategoryID)
+	}
+	if filter.IsAvailable != nil {
+		query = query.Where("is_available = ?", *filter.IsAvailable)
+	}
+
+	return findPage[models.Dish](query, page, dishSortColumns, "id")
+}
+
+// Update saves the dish. Stock is left alone, since orders change it concurrently
+// and it is only set through UpdateStock, and so is the rating derived from reviews.
 func (dao *DishDAO) Update(dish *models.Dish) error {
-	return dao.db.Save(dish).Error
+	return dao.db.Omit("DailyStock", "StockRemaining", "StockResetAt", "Rating", "RatingCount").Save(dish).Error
+}
+
+// AdjustStock changes the remaining stock of a dish that tracks stock by delta,
+// never going below zero. The dish becomes unavailable when it sells out and
+// available again when stock returns to a sold out dish.
+func (dao *DishDAO) AdjustStock(dishID uint, delta int) error {
+	return dao.db.Model(&models.Dish{}).
+		Where("id = ? AND stock_remaining IS NOT NULL", dishID).
+		Updates(map[string]interface{}{
+			"stock_remaining": gorm.Expr("GREATEST(stock_remaining + ?, 0)", delta),
+			"is_available": gorm.Expr("CASE WHEN stock_remaining + ? <= 0 THEN false "+
+				"WHEN stock_remaining <= 0 THEN true ELSE is_available END", delta),
+		}).Error
+}
+
+// UpdateStock sets the daily and remaining stock of a dish and its availability
+func (dao *DishDAO) UpdateStock(dishID uint, dailyStock, stockRemaining *int, isAvailable bool, resetAt *time.Time) error {
+	return dao.db.Model(&models.Dish{}).Where("id = ?", dishID).
+		Updates(map[string]interface{}{
+			"daily_stock":     dailyStock,
+			"stock_remaining": stockRemaining,
+			"stock_reset_at":  resetAt,
+			"is_available":    isAvailable,
+		}).Error
+}
+
+// ResetDailyStock refills the stock of every dish whose last reset was before the
+// current day in its restaurant's time zone. Dishes that had sold out become
+// available again. It returns the number of dishes reset.
+func (dao *DishDAO) ResetDailyStock(now time.Time) (int64, error) {
+	result := dao.db.Exec(`
+		UPDATE dishes
+		SET stock_remaining = daily_stock,
+			stock_reset_at = @now,
+			is_available = CASE WHEN daily_stock = 0 THEN false
+				WHEN stock_remaining <= 0 THEN true
+				ELSE is_available END
+		FROM restaurants
+		WHERE restaurants.id = dishes.restaurant_id
+			AND dishes.daily_stock IS NOT NULL
+			AND (dishes.stock_reset_at IS NULL
+				OR (dishes.stock_reset_at AT TIME ZONE restaurants.time_zone)::date
+					< (CAST(@now AS TIMESTAMP WITH TIME ZONE) AT TIME ZONE restaurants.time_zone)::date)`,
+		sql.Named("now", now))
+	return result.RowsAffected, result.Error
 }
 
 func (dao *DishDAO) Delete(id uint) error {
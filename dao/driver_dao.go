@@ -0,0 +1,79 @@
+package dao
+
+import (
+	"math"
+	"time"
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+type DriverDAO struct {
+	db *gorm.DB
+}
+
+func NewDriverDAO(db *gorm.DB) *DriverDAO {
+	return &DriverDAO{db: db}
+}
+
+func (dao *DriverDAO) GetByUserID(userID uint) (*models.Driver, error) {
+	var driver models.Driver
+	err := dao.db.Preload("User").Where("user_id = ?", userID).First(&driver).Error
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+// Save creates the driver profile or updates it when it has an ID
+func (dao *DriverDAO) Save(driver *models.Driver) error {
+	return dao.db.Omit("User").Save(driver).Error
+}
+
+func (dao *DriverDAO) UpdateAvailability(userID uint, available bool) error {
+	return dao.db.Model(&models.Driver{}).Where("user_id = ?", userID).
+		Update("is_available", available).Error
+}
+
+// UpdateLocation records where the driver was last seen
+func (dao *DriverDAO) UpdateLocation(userID uint, lat, lng float64, at time.Time) error {
+	return dao.db.Model(&models.Driver{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"latitude": lat, "longitude": lng, "location_updated_at": at}).Error
+}
+
+// GetAvailableNear returns the available drivers seen since the given time within
+// radiusKm of the point, nearest first. Drivers with an open offer or an active
+// delivery are left out, and so are drivers already offered the given order.
+func (dao *DriverDAO) GetAvailableNear(lat, lng, radiusKm float64, seenSince time.Time, orderID uint, limit int) ([]models.Driver, error) {
+	latDelta := radiusKm / 111.045
+	lngDelta := latDelta / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+
+	var drivers []models.Driver
+	err := dao.db.Model(&models.Driver{}).
+		Select("drivers.*, "+haversineSQL+" AS distance_km", lat, lat, lng).
+		Where("drivers.is_available = ? AND drivers.location_updated_at >= ?", true, seenSince).
+		// Users whose driver role was taken away keep their profile but get no offers
+		Where("EXISTS (SELECT 1 FROM users WHERE users.id = drivers.user_id AND users.role = ?)", models.UserRoleDriver).
+		Where("latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta).
+		Where("longitude BETWEEN ? AND ?", lng-lngDelta, lng+lngDelta).
+		Where(haversineSQL+" <= ?", lat, lat, lng, radiusKm).
+		Where("NOT EXISTS (SELECT 1 FROM delivery_offers WHERE delivery_offers.driver_id = drivers.user_id AND (delivery_offers.status = ? OR delivery_offers.order_id = ?))",
+			models.DeliveryOfferStatusOffered, orderID).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.driver_id = drivers.user_id AND orders.status IN ?)",
+			models.ActiveDeliveryStatuses).
+		Order("distance_km, drivers.id").
+		Limit(limit).
+		Find(&drivers).Error
+	return drivers, err
+}
+
+func (dao *DriverDAO) CreateLocation(location *models.DriverLocation) error {
+	return dao.db.Create(location).Error
+}
+
+// GetLocationsByOrderID returns the location pings recorded while delivering the order, oldest first
+func (dao *DriverDAO) GetLocationsByOrderID(orderID uint) ([]models.DriverLocation, error) {
+	var locations []models.DriverLocation
+	err := dao.db.Where("order_id = ?", orderID).Order("created_at, id").Find(&locations).Error
+	return locations, err
+}
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+// MenuDAO stores the menu categories of restaurants
+type MenuDAO struct {
+	db *gorm.DB
+}
+
+func NewMenuDAO(db *gorm.DB) *MenuDAO {
+	return &MenuDAO{db: db}
+}
+
+func (dao *MenuDAO) CreateCategory(category *models.MenuCategory) error {
+	return dao.db.Omit("Dishes").Create(category).Error
+}
+
+func (dao *MenuDAO) GetCategoryByID(id uint) (*models.MenuCategory, error) {
+	var category models.MenuCategory
+	if err := dao.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetCategoriesByIDs returns the given menu categories in any order
+func (dao *MenuDAO) GetCategoriesByIDs(ids []uint) ([]models.MenuCategory, error) {
+	var categories []models.MenuCategory
+	err := dao.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// GetCategoriesByRestaurantID returns the menu categories of a restaurant in display order
+func (dao *MenuDAO) GetCategoriesByRestaurantID(restaurantID uint) ([]models.MenuCategory, error) {
+	var categories []models.MenuCategory
+	err := dao.db.Where("restaurant_id = ?", restaurantID).
+		Order("display_order, id").Find(&categories).Error
+	return categories, err
+}
+
+// GetAvailableDishes returns the available dishes of a restaurant with their
+// modifiers, ordered by their position within a category
+func (dao *MenuDAO) GetAvailableDishes(restaurantID uint) ([]models.Dish, error) {
+	var dishes []models.Dish
+	err := preloadModifiers(dao.db, "ModifierGroups").
+		Where("restaurant_id = ? AND is_available = ?", restaurantID, true).
+		Order("display_order, name, id").Find(&dishes).Error
+	return dishes, err
+}
+
+func (dao *MenuDAO) UpdateCategory(category *models.MenuCategory) error {
+	return dao.db.Omit("Dishes").Save(category).Error
+}
+
+// DeleteCategory deletes the category. Its dishes stay on the menu without a category.
+func (dao *MenuDAO) DeleteCategory(id uint) error {
+	return dao.db.Delete(&models.MenuCategory{}, id).Error
+}
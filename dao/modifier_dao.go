@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+type ModifierDAO struct {
+	db *gorm.DB
+}
+
+func NewModifierDAO(db *gorm.DB) *ModifierDAO {
+	return &ModifierDAO{db: db}
+}
+
+// preloadModifiers loads the modifier groups and options of dishes in display order
+func preloadModifiers(db *gorm.DB, field string) *gorm.DB {
+	return db.Preload(field, func(db *gorm.DB) *gorm.DB {
+		return db.Order("modifier_groups.display_order, modifier_groups.id")
+	}).Preload(field+".Options", orderOptions)
+}
+
+func orderOptions(db *gorm.DB) *gorm.DB {
+	return db.Order("modifier_options.display_order, modifier_options.id")
+}
+
+// CreateGroup creates the group together with its options
+func (dao *ModifierDAO) CreateGroup(group *models.ModifierGroup) error {
+	return dao.db.Create(group).Error
+}
+
+func (dao *ModifierDAO) GetGroupByID(id uint) (*models.ModifierGroup, error) {
+	var group models.ModifierGroup
+	err := dao.db.Preload("Options", orderOptions).First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// GetGroupsByDishIDs returns the modifier groups of the dishes with their options
+func (dao *ModifierDAO) GetGroupsByDishIDs(dishIDs ...uint) ([]models.ModifierGroup, error) {
+	var groups []models.ModifierGroup
+	err := dao.db.Preload("Options", orderOptions).Where("dish_id IN ?", dishIDs).
+		Order("display_order, id").Find(&groups).Error
+	return groups, err
+}
+
+// UpdateGroup saves the group and makes its options match group.Options. Options
+// with an ID are updated, options without one are created and all other options of
+// the group are deleted.
+func (dao *ModifierDAO) UpdateGroup(group *models.ModifierGroup) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Options").Save(group).Error; err != nil {
+			return err
+		}
+
+		keep := make([]uint, 0, len(group.Options))
+		for _, option := range group.Options {
+			if option.ID != 0 {
+				keep = append(keep, option.ID)
+			}
+		}
+		query := tx.Where("modifier_group_id = ?", group.ID)
+		if len(keep) > 0 {
+			query = query.Where("id NOT IN ?", keep)
+		}
+		if err := query.Delete(&models.ModifierOption{}).Error; err != nil {
+			return err
+		}
+
+		for i := range group.Options {
+			group.Options[i].ModifierGroupID = group.ID
+			if err := tx.Save(&group.Options[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (dao *ModifierDAO) DeleteGroup(id uint) error {
+	return dao.db.Delete(&models.ModifierGroup{}, id).Error
+}
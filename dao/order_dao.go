@@ -1,9 +1,13 @@
 package dao
 
 import (
+	"errors"
+	"strings"
+	"time"
 	"tumdum_backend/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderDAO struct {
@@ -21,36 +25,306 @@ func (dao *OrderDAO) Create(order *models.Order) error {
 func (dao *OrderDAO) GetByID(id uint) (*models.Order, error) {
 	var order models.Order
 	err := dao.db.Preload("User").Preload("Restaurant").Preload("OrderItems.Dish").
-		First(&order, id).Error
+		Preload("OrderItems.Modifiers").Preload("Discounts").Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("payments.id")
+	}).First(&order, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
-func (dao *OrderDAO) GetByUserID(userID uint) ([]models.Order, error) {
+// GetByIDForUpdate loads the order without associations and locks its row until
+// the surrounding transaction ends
+func (dao *OrderDAO) GetByIDForUpdate(id uint) (*models.Order, error) {
+	var order models.Order
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateStatus moves the order from one status to another. It fails if the order
+// is no longer in the expected status.
+func (dao *OrderDAO) UpdateStatus(orderID uint, from, to models.OrderStatus) error {
+	result := dao.db.Model(&models.Order{}).
+		Where("id = ? AND status = ?", orderID, from).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("order status was changed concurrently")
+	}
+	return nil
+}
+
+// Update saves the order and replaces its items, with their modifiers, by the
+// ones on the order, so it should run inside a transaction. Item IDs sent by the
+// client are never reused. Discount lines are saved with UpdateDiscounts.
+func (dao *OrderDAO) Update(order *models.Order) error {
+	// Deleting the items deletes their modifiers too
+	if err := dao.db.Where("order_id = ?", order.ID).Delete(&models.OrderItem{}).Error; err != nil {
+		return err
+	}
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		item.ID = 0
+		item.OrderID = order.ID
+		for j := range item.Modifiers {
+			item.Modifiers[j].ID = 0
+			item.Modifiers[j].OrderItemID = 0
+		}
+	}
+	if len(order.OrderItems) > 0 {
+		if err := dao.db.Omit("Order", "Dish").Create(&order.OrderItems).Error; err != nil {
+			return err
+		}
+	}
+	return dao.db.Omit(clause.Associations).Save(order).Error
+}
+
+// UpdateDiscounts saves the amounts of existing discount lines
+func (dao *OrderDAO) UpdateDiscounts(discounts []models.OrderDiscount) error {
+	for _, discount := range discounts {
+		err := dao.db.Model(&models.OrderDiscount{}).Where("id = ?", discount.ID).
+			Updates(map[string]interface{}{"amount_amount": discount.Amount.Amount, "amount_currency": discount.Amount.Currency}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountActiveByUserID counts the orders of a user that were not cancelled
+func (dao *OrderDAO) CountActiveByUserID(userID uint) (int64, error) {
+	var count int64
+	err := dao.db.Model(&models.Order{}).
+		Where("user_id = ? AND status <> ?", userID, models.OrderStatusCancelled).
+		Count(&count).Error
+	return count, err
+}
+
+// GetAwaitingDriver returns ready orders that have no driver and no open delivery
+// offer, oldest first, with their restaurants
+func (dao *OrderDAO) GetAwaitingDriver(limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := dao.db.Preload("Restaurant").
+		Where("status = ? AND driver_id IS NULL", models.OrderStatusReady).
+		Where("NOT EXISTS (SELECT 1 FROM delivery_offers WHERE delivery_offers.order_id = orders.id AND delivery_offers.status = ?)",
+			models.DeliveryOfferStatusOffered).
+		Order("updated_at, id").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// AssignDriver hands a ready order to a driver. It fails if the order already has
+// a driver or is no longer ready.
+func (dao *OrderDAO) AssignDriver(orderID, driverID uint) error {
+	result := dao.db.Model(&models.Order{}).
+		Where("id = ? AND driver_id IS NULL AND status = ?", orderID, models.OrderStatusReady).
+		Update("driver_id", driverID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("order is no longer awaiting a driver")
+	}
+	return nil
+}
+
+// GetActiveByDriverID returns the orders the driver is delivering with their restaurants
+func (dao *OrderDAO) GetActiveByDriverID(driverID uint) ([]models.Order, error) {
 	var orders []models.Order
 	err := dao.db.Preload("Restaurant").Preload("OrderItems.Dish").
-		Where("user_id = ?", userID).Find(&orders).Error
+		Where("driver_id = ? AND status IN ?", driverID, models.ActiveDeliveryStatuses).
+		Order("id").Find(&orders).Error
 	return orders, err
 }
 
-func (dao *OrderDAO) UpdateStatus(orderID uint, status models.OrderStatus) error {
-	return dao.db.Model(&models.Order{}).Where("id = ?", orderID).
-		Update("status", status).Error
+// GetKitchenOrders returns the orders of a restaurant in the given statuses with
+// the time each entered its current status, longest waiting first
+func (dao *OrderDAO) GetKitchenOrders(restaurantID uint, statuses []models.OrderStatus) ([]models.Order, error) {
+	var orders []models.Order
+	err := dao.db.Preload("OrderItems.Dish").Preload("OrderItems.Modifiers").
+		Select("orders.*, COALESCE((SELECT MAX(e.created_at) FROM order_status_events e "+
+			"WHERE e.order_id = orders.id AND e.to_status = orders.status AND e.from_status <> e.to_status), "+
+			"orders.updated_at) AS status_changed_at").
+		Where("restaurant_id = ? AND status IN ?", restaurantID, statuses).
+		Order("status_changed_at, id").Find(&orders).Error
+	return orders, err
 }
 
-func (dao *OrderDAO) Update(order *models.Order) error {
-	return dao.db.Save(order).Error
+// CountInKitchen counts the other orders of a restaurant in the given statuses
+func (dao *OrderDAO) CountInKitchen(restaurantID, excludeOrderID uint, statuses []models.OrderStatus) (int64, error) {
+	var count int64
+	err := dao.db.Model(&models.Order{}).
+		Where("restaurant_id = ? AND id <> ? AND status IN ?", restaurantID, excludeOrderID, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+// UpdateEstimates saves the estimated ready and delivery times of an order
+func (dao *OrderDAO) UpdateEstimates(order *models.Order) error {
+	return dao.db.Model(&models.Order{}).Where("id = ?", order.ID).
+		Updates(map[string]interface{}{
+			"estimated_ready_at":    order.EstimatedReadyAt,
+			"estimated_delivery_at": order.EstimatedDeliveryAt,
+		}).Error
 }
 
-func (dao *OrderDAO) GetAll() ([]models.Order, error) {
+// RefundItems adds refunded quantities to the items of an order, keyed by order item ID
+func (dao *OrderDAO) RefundItems(orderID uint, quantities map[uint]int) error {
+	for itemID, quantity := range quantities {
+		result := dao.db.Model(&models.OrderItem{}).
+			Where("id = ? AND order_id = ? AND refunded_quantity + ? <= quantity", itemID, orderID, quantity).
+			Update("refunded_quantity", gorm.Expr("refunded_quantity + ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("refund exceeds the ordered quantity")
+		}
+	}
+	return nil
+}
+
+// UpdateTotals writes the price breakdown of an order without touching its other columns
+func (dao *OrderDAO) UpdateTotals(order *models.Order) error {
+	return dao.db.Model(&models.Order{}).Where("id = ?", order.ID).
+		Updates(map[string]interface{}{
+			"subtotal_amount":        order.SubtotalAmount.Amount,
+			"subtotal_currency":      order.SubtotalAmount.Currency,
+			"discount_amount":        order.DiscountAmount.Amount,
+			"discount_currency":      order.DiscountAmount.Currency,
+			"tax_amount":             order.TaxAmount.Amount,
+			"tax_currency":           order.TaxAmount.Currency,
+			"delivery_fee_amount":    order.DeliveryFee.Amount,
+			"delivery_fee_currency":  order.DeliveryFee.Currency,
+			"packaging_fee_amount":   order.PackagingFee.Amount,
+			"packaging_fee_currency": order.PackagingFee.Currency,
+			"tip_amount":             order.TipAmount.Amount,
+			"tip_currency":           order.TipAmount.Currency,
+			"total_amount":           order.TotalAmount.Amount,
+			"total_currency":         order.TotalAmount.Currency,
+		}).Error
+}
+
+func (dao *OrderDAO) CreateStatusEvent(event *models.OrderStatusEvent) error {
+	return dao.db.Create(event).Error
+}
+
+func (dao *OrderDAO) GetStatusEvents(orderID uint) ([]models.OrderStatusEvent, error) {
+	var events []models.OrderStatusEvent
+	// Only the actor's role is loaded; the timeline is shown to customers
+	err := dao.db.Select("order_status_events.*, (SELECT role FROM users WHERE users.id = order_status_events.actor_id) AS actor_role").
+		Where("order_id = ?", orderID).
+		Order("created_at, id").Find(&events).Error
+	return events, err
+}
+
+var orderSortColumns = sortColumns{
+	"id":            "id",
+	"created_at":    "created_at",
+	"updated_at":    "updated_at",
+	"status":        "status",
+	"total_amount":  "total_amount",
+	"scheduled_for": "scheduled_for",
+}
+
+// GetAll returns a page of orders matching the filter. Associations are only
+// preloaded for the orders on the page.
+func (dao *OrderDAO) GetAll(filter models.OrderFilter, page models.PageRequest) (*models.Page[models.Order], error) {
+	query := dao.db.Model(&models.Order{}).
+		Preload("User").Preload("Restaurant").Preload("OrderItems.Dish").
+		Preload("OrderItems.Modifiers").Preload("Discounts")
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.RestaurantID != 0 {
+		query = query.Where("restaurant_id = ?", filter.RestaurantID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+
+	defaultSort := "-created_at"
+	if filter.Upcoming {
+		query = query.Where("scheduled_for > ? AND status NOT IN ?", time.Now(),
+			[]models.OrderStatus{models.OrderStatusDelivered, models.OrderStatusCancelled})
+		defaultSort = "scheduled_for"
+	}
+	// Only scheduled orders have a slot, so they are the only ones that can be sorted by it
+	sort := strings.TrimPrefix(page.Sort, "-")
+	if sort == "scheduled_for" || (sort == "" && defaultSort == "scheduled_for") {
+		query = query.Where("scheduled_for IS NOT NULL")
+	}
+
+	return findPage[models.Order](query, page, orderSortColumns, defaultSort)
+}
+
+// CountScheduled counts the orders of a restaurant scheduled within [from, to),
+// ignoring cancelled ones
+func (dao *OrderDAO) CountScheduled(restaurantID uint, from, to time.Time) (int64, error) {
+	var count int64
+	err := dao.db.Model(&models.Order{}).
+		Where("restaurant_id = ? AND scheduled_for >= ? AND scheduled_for < ? AND status <> ?",
+			restaurantID, from, to, models.OrderStatusCancelled).
+		Count(&count).Error
+	return count, err
+}
+
+// CountScheduledBySlot counts the non-cancelled orders of a restaurant scheduled
+// within [from, to), grouped by their scheduled time
+func (dao *OrderDAO) CountScheduledBySlot(restaurantID uint, from, to time.Time) (map[time.Time]int64, error) {
+	var rows []struct {
+		ScheduledFor time.Time
+		Count        int64
+	}
+	err := dao.db.Model(&models.Order{}).
+		Select("scheduled_for, COUNT(*) AS count").
+		Where("restaurant_id = ? AND scheduled_for >= ? AND scheduled_for < ? AND status <> ?",
+			restaurantID, from, to, models.OrderStatusCancelled).
+		Group("scheduled_for").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ScheduledFor.UTC()] = row.Count
+	}
+	return counts, nil
+}
+
+// GetDueScheduled returns scheduled orders with their items whose slot starts at
+// or before the cutoff, earliest first
+func (dao *OrderDAO) GetDueScheduled(cutoff time.Time, limit int) ([]models.Order, error) {
 	var orders []models.Order
-	err := dao.db.Preload("User").Preload("Restaurant").Preload("OrderItems.Dish").
+	err := dao.db.Preload("OrderItems").Where("status = ? AND scheduled_for <= ?", models.OrderStatusScheduled, cutoff).
+		Order("scheduled_for, id").
+		Limit(limit).
 		Find(&orders).Error
 	return orders, err
 }
 
+// SetStockReservedAt records when the order's items were taken from stock, nil
+// once they have been returned
+func (dao *OrderDAO) SetStockReservedAt(orderID uint, reservedAt *time.Time) error {
+	return dao.db.Model(&models.Order{}).Where("id = ?", orderID).
+		Update("stock_reserved_at", reservedAt).Error
+}
+
 func (dao *OrderDAO) Delete(id uint) error {
 	return dao.db.Delete(&models.Order{}, id).Error
 }
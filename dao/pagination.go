@@ -0,0 +1,169 @@
+package dao
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+// sortColumns maps the sort names accepted by a list endpoint to table columns
+type sortColumns map[string]string
+
+// cursor is the decoded form of the opaque next_cursor token. It holds the sort
+// value and ID of the last row of the previous page.
+type cursor struct {
+	Sort  string `json:"s"`
+	Kind  string `json:"k,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    uint   `json:"id"`
+}
+
+// findPage runs a keyset-paginated query ordered by the requested sort column and
+// then by ID, so rows with equal sort values are never skipped or repeated
+func findPage[T any](query *gorm.DB, page models.PageRequest, columns sortColumns, defaultSort string) (*models.Page[T], error) {
+	sort := page.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	name, descending := strings.CutPrefix(sort, "-")
+	column, ok := columns[name]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	limit := page.Limit
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+
+	operator, direction := ">", "ASC"
+	if descending {
+		operator, direction = "<", "DESC"
+	}
+
+	if page.Cursor != "" {
+		after, err := decodeCursor(page.Cursor)
+		if err != nil || after.Sort != sort {
+			return nil, ErrInvalidCursor
+		}
+		if column == "id" {
+			query = query.Where(fmt.Sprintf("id %s ?", operator), after.ID)
+		} else {
+			value, err := after.value()
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, operator), value, after.ID)
+		}
+	}
+
+	if column != "id" {
+		query = query.Order(fmt.Sprintf("%s %s", column, direction))
+	}
+	query = query.Order(fmt.Sprintf("id %s", direction))
+
+	var rows []T
+	if err := query.Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := &models.Page[T]{Data: rows, Limit: limit}
+	if len(rows) > limit {
+		result.Data = rows[:limit]
+		next, err := encodeCursor(query, &rows[limit-1], sort, column)
+		if err != nil {
+			return nil, err
+		}
+		result.NextCursor = next
+	}
+	return result, nil
+}
+
+// encodeCursor builds the cursor pointing after the given row
+func encodeCursor(db *gorm.DB, row any, sort, column string) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(row); err != nil {
+		return "", err
+	}
+	rowValue := reflect.ValueOf(row).Elem()
+
+	id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(context.Background(), rowValue)
+	idValue, ok := id.(uint)
+	if !ok {
+		return "", errors.New("paginated rows must have a uint primary key")
+	}
+	next := cursor{Sort: sort, ID: idValue}
+
+	if column != "id" {
+		field := stmt.Schema.LookUpField(column)
+		if field == nil {
+			return "", ErrInvalidSort
+		}
+		value, _ := field.ValueOf(context.Background(), rowValue)
+		if t, ok := value.(*time.Time); ok && t != nil {
+			value = *t
+		}
+		switch v := value.(type) {
+		case time.Time:
+			next.Kind, next.Value = "time", v.Format(time.RFC3339Nano)
+		case int, int32, int64, uint, uint32, uint64:
+			next.Kind, next.Value = "int", fmt.Sprint(v)
+		case float32:
+			next.Kind, next.Value = "float", strconv.FormatFloat(float64(v), 'f', -1, 32)
+		case float64:
+			next.Kind, next.Value = "float", strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			next.Kind, next.Value = "string", fmt.Sprint(v)
+		}
+	}
+
+	data, err := json.Marshal(next)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// value restores the typed sort value stored in the cursor
+func (c *cursor) value() (any, error) {
+	switch c.Kind {
+	case "time":
+		return time.Parse(time.RFC3339Nano, c.Value)
+	case "int":
+		return strconv.ParseInt(c.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(c.Value, 64)
+	case "string":
+		return c.Value, nil
+	}
+	return nil, ErrInvalidCursor
+}
@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"time"
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentDAO struct {
+	db *gorm.DB
+}
+
+func NewPaymentDAO(db *gorm.DB) *PaymentDAO {
+	return &PaymentDAO{db: db}
+}
+
+func (dao *PaymentDAO) Create(payment *models.Payment) error {
+	return dao.db.Create(payment).Error
+}
+
+func (dao *PaymentDAO) Update(payment *models.Payment) error {
+	return dao.db.Save(payment).Error
+}
+
+// GetByOrderID returns the payment attempts of an order, oldest first
+func (dao *PaymentDAO) GetByOrderID(orderID uint) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := dao.db.Where("order_id = ?", orderID).Order("id").Find(&payments).Error
+	return payments, err
+}
+
+// GetActiveByOrderIDForUpdate returns the authorized or captured payment of an
+// order, or nil if it has none, and locks it until the surrounding transaction ends
+func (dao *PaymentDAO) GetActiveByOrderIDForUpdate(orderID uint) (*models.Payment, error) {
+	var payments []models.Payment
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("order_id = ? AND status IN ?", orderID,
+			[]models.PaymentStatus{models.PaymentStatusAuthorized, models.PaymentStatusCaptured}).
+		Order("id DESC").Limit(1).Find(&payments).Error
+	if err != nil || len(payments) == 0 {
+		return nil, err
+	}
+	return &payments[0], nil
+}
+
+// GetByProviderReferenceForUpdate finds a payment by the provider's reference and
+// locks it until the surrounding transaction ends
+func (dao *PaymentDAO) GetByProviderReferenceForUpdate(provider, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("provider = ? AND provider_reference = ?", provider, reference).
+		First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// RecordSettleFailure keeps a failed settlement of the payment pending until retryAt
+func (dao *PaymentDAO) RecordSettleFailure(id uint, reason string, retryAt time.Time) error {
+	return dao.db.Model(&models.Payment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"settle_error":    reason,
+		"settle_attempts": gorm.Expr("settle_attempts + 1"),
+		"settle_retry_at": retryAt,
+	}).Error
+}
+
+// GetDueSettlements returns up to limit authorized or captured payments whose
+// failed settlement is due to be tried again, longest waiting first
+func (dao *PaymentDAO) GetDueSettlements(now time.Time, limit int) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := dao.db.Where("settle_retry_at <= ? AND status IN ?", now,
+		[]models.PaymentStatus{models.PaymentStatusAuthorized, models.PaymentStatusCaptured}).
+		Order("settle_retry_at, id").Limit(limit).Find(&payments).Error
+	return payments, err
+}
@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromotionDAO struct {
+	db *gorm.DB
+}
+
+func NewPromotionDAO(db *gorm.DB) *PromotionDAO {
+	return &PromotionDAO{db: db}
+}
+
+func (dao *PromotionDAO) Create(promotion *models.Promotion) error {
+	return dao.db.Create(promotion).Error
+}
+
+func (dao *PromotionDAO) GetByID(id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := dao.db.First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (dao *PromotionDAO) GetByCode(code string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := dao.db.Where("code = ?", code).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// GetByCodeForUpdate finds a promotion by its code and locks it until the
+// surrounding transaction ends, so its redemption limits hold under concurrency
+func (dao *PromotionDAO) GetByCodeForUpdate(code string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&promotion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+var promotionSortColumns = sortColumns{
+	"id":         "id",
+	"code":       "code",
+	"created_at": "created_at",
+	"starts_at":  "starts_at",
+	"ends_at":    "ends_at",
+}
+
+func (dao *PromotionDAO) GetAll(filter models.PromotionFilter, page models.PageRequest) (*models.Page[models.Promotion], error) {
+	query := dao.db.Model(&models.Promotion{})
+	if filter.Code != "" {
+		query = query.Where("code = ?", filter.Code)
+	}
+	if filter.RestaurantID != 0 {
+		query = query.Where("restaurant_id = ?", filter.RestaurantID)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	return findPage[models.Promotion](query, page, promotionSortColumns, "id")
+}
+
+func (dao *PromotionDAO) Update(promotion *models.Promotion) error {
+	return dao.db.Save(promotion).Error
+}
+
+func (dao *PromotionDAO) Delete(id uint) error {
+	return dao.db.Delete(&models.Promotion{}, id).Error
+}
+
+func (dao *PromotionDAO) CreateRedemption(redemption *models.PromotionRedemption) error {
+	return dao.db.Create(redemption).Error
+}
+
+// CountRedemptions counts the uses of a promotion, by one user when userID is not 0
+func (dao *PromotionDAO) CountRedemptions(promotionID, userID uint) (int64, error) {
+	query := dao.db.Model(&models.PromotionRedemption{}).Where("promotion_id = ?", promotionID)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// DeleteRedemptionsByOrderID gives the promotions used by an order back to the customer
+func (dao *PromotionDAO) DeleteRedemptionsByOrderID(orderID uint) error {
+	return dao.db.Where("order_id = ?", orderID).Delete(&models.PromotionRedemption{}).Error
+}
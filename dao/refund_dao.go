@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundDAO struct {
+	db *gorm.DB
+}
+
+func NewRefundDAO(db *gorm.DB) *RefundDAO {
+	return &RefundDAO{db: db}
+}
+
+// Create saves the refund together with its items
+func (dao *RefundDAO) Create(refund *models.Refund) error {
+	return dao.db.Create(refund).Error
+}
+
+// Update saves the refund without touching its items
+func (dao *RefundDAO) Update(refund *models.Refund) error {
+	return dao.db.Omit("Items").Save(refund).Error
+}
+
+// GetByIDForUpdate loads the refund with its items and locks its row until the
+// surrounding transaction ends
+func (dao *RefundDAO) GetByIDForUpdate(id uint) (*models.Refund, error) {
+	var refund models.Refund
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&refund, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// GetByOrderID returns the refunds of an order, oldest first
+func (dao *RefundDAO) GetByOrderID(orderID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := dao.db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("refund_items.id")
+	}).Where("order_id = ?", orderID).Order("id").Find(&refunds).Error
+	return refunds, err
+}
+
+func (dao *RefundDAO) CountByOrderID(orderID uint) (int64, error) {
+	var count int64
+	err := dao.db.Model(&models.Refund{}).Where("order_id = ?", orderID).Count(&count).Error
+	return count, err
+}
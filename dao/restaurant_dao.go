@@ -1,10 +1,11 @@
 package dao
 
 import (
-	"strconv"
+	"math"
 	"tumdum_backend/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RestaurantDAO struct {
@@ -16,7 +17,21 @@ func NewRestaurantDAO(db *gorm.DB) *RestaurantDAO {
 }
 
 func (dao *RestaurantDAO) Create(restaurant *models.Restaurant) error {
-	return dao.db.Create(restaurant).Error
+	return dao.db.Omit(clause.Associations).Create(restaurant).Error
+}
+
+// CreateWithOwner creates the restaurant and registers the given user as its owner
+func (dao *RestaurantDAO) CreateWithOwner(restaurant *models.Restaurant, ownerID uint) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(restaurant).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RestaurantMember{
+			RestaurantID: restaurant.ID,
+			UserID:       ownerID,
+			Role:         models.MemberRoleOwner,
+		}).Error
+	})
 }
 
 func (dao *RestaurantDAO) GetByID(id uint) (*models.Restaurant, error) {
@@ -28,30 +43,75 @@ func (dao *RestaurantDAO) GetByID(id uint) (*models.Restaurant, error) {
 	return &restaurant, nil
 }
 
-func (dao *RestaurantDAO) GetAll(cuisine, isActive, city string) ([]models.Restaurant, error) {
-	var restaurants []models.Restaurant
+// GetByIDForUpdate loads the restaurant and locks its row until the surrounding
+// transaction ends
+func (dao *RestaurantDAO) GetByIDForUpdate(id uint) (*models.Restaurant, error) {
+	var restaurant models.Restaurant
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&restaurant, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &restaurant, nil
+}
+
+var restaurantSortColumns = sortColumns{
+	"id":         "id",
+	"name":       "name",
+	"rating":     "rating",
+	"created_at": "created_at",
+}
+
+func (dao *RestaurantDAO) GetAll(filter models.RestaurantFilter, page models.PageRequest) (*models.Page[models.Restaurant], error) {
 	query := dao.db.Model(&models.Restaurant{})
 
 	// Apply filters if provided
-	if cuisine != "" {
-		query = query.Where("cuisine = ?", cuisine)
+	if filter.Cuisine != "" {
+		query = query.Where("cuisine = ?", filter.Cuisine)
 	}
-	if isActive != "" {
-		active, err := strconv.ParseBool(isActive)
-		if err == nil {
-			query = query.Where("is_active = ?", active)
-		}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.City != "" {
+		query = query.Where("city = ?", filter.City)
 	}
-	if city != "" {
-		query = query.Where("city = ?", city)
+	if filter.MinRating != nil {
+		query = query.Where("rating >= ?", *filter.MinRating)
 	}
 
-	err := query.Find(&restaurants).Error
+	return findPage[models.Restaurant](query, page, restaurantSortColumns, "id")
+}
+
+// haversineSQL computes the distance in kilometres between the restaurant and a
+// point. Its arguments are the point's latitude twice followed by its longitude.
+const haversineSQL = `2 * 6371 * asin(sqrt(
+	power(sin(radians(latitude - ?) / 2), 2) +
+	cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2)))`
+
+// GetNearby returns the active restaurants within radiusKm of the point, nearest first,
+// with DistanceKm set
+func (dao *RestaurantDAO) GetNearby(lat, lng, radiusKm float64, limit int) ([]models.Restaurant, error) {
+	// A bounding box lets the location index discard far away rows before the
+	// exact distance is computed
+	latDelta := radiusKm / 111.045
+	lngDelta := latDelta / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+
+	var restaurants []models.Restaurant
+	err := dao.db.Model(&models.Restaurant{}).
+		Select("restaurants.*, "+haversineSQL+" AS distance_km", lat, lat, lng).
+		Where("is_active = ?", true).
+		Where("latitude BETWEEN ? AND ?", lat-latDelta, lat+latDelta).
+		Where("longitude BETWEEN ? AND ?", lng-lngDelta, lng+lngDelta).
+		Where(haversineSQL+" <= ?", lat, lat, lng, radiusKm).
+		Order("distance_km, id").
+		Limit(limit).
+		Find(&restaurants).Error
 	return restaurants, err
 }
 
+// Update saves the restaurant. The rating is left alone, since it is derived
+// from reviews and only set through ReviewDAO.RefreshRatings.
 func (dao *RestaurantDAO) Update(restaurant *models.Restaurant) error {
-	return dao.db.Save(restaurant).Error
+	return dao.db.Omit(clause.Associations, "Rating", "ReviewCount").Save(restaurant).Error
 }
 
 func (dao *RestaurantDAO) Delete(id uint) error {
@@ -0,0 +1,56 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+type RestaurantMemberDAO struct {
+	db *gorm.DB
+}
+
+func NewRestaurantMemberDAO(db *gorm.DB) *RestaurantMemberDAO {
+	return &RestaurantMemberDAO{db: db}
+}
+
+func (dao *RestaurantMemberDAO) Create(member *models.RestaurantMember) error {
+	return dao.db.Create(member).Error
+}
+
+func (dao *RestaurantMemberDAO) GetByID(id uint) (*models.RestaurantMember, error) {
+	var member models.RestaurantMember
+	err := dao.db.Preload("User").First(&member, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (dao *RestaurantMemberDAO) GetByRestaurantAndUser(restaurantID, userID uint) (*models.RestaurantMember, error) {
+	var member models.RestaurantMember
+	err := dao.db.Where("restaurant_id = ? AND user_id = ?", restaurantID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (dao *RestaurantMemberDAO) GetByRestaurantID(restaurantID uint) ([]models.RestaurantMember, error) {
+	var members []models.RestaurantMember
+	err := dao.db.Preload("User").Where("restaurant_id = ?", restaurantID).
+		Order("id").Find(&members).Error
+	return members, err
+}
+
+func (dao *RestaurantMemberDAO) CountByRole(restaurantID uint, role models.MemberRole) (int64, error) {
+	var count int64
+	err := dao.db.Model(&models.RestaurantMember{}).
+		Where("restaurant_id = ? AND role = ?", restaurantID, role).Count(&count).Error
+	return count, err
+}
+
+func (dao *RestaurantMemberDAO) Delete(id uint) error {
+	return dao.db.Delete(&models.RestaurantMember{}, id).Error
+}
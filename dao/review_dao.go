@@ -0,0 +1,116 @@
+package dao
+
+import (
+	"database/sql"
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewDAO struct {
+	db *gorm.DB
+}
+
+func NewReviewDAO(db *gorm.DB) *ReviewDAO {
+	return &ReviewDAO{db: db}
+}
+
+// Create saves the review together with its dish ratings
+func (dao *ReviewDAO) Create(review *models.Review) error {
+	return dao.db.Create(review).Error
+}
+
+// Update saves the review without touching its dish ratings
+func (dao *ReviewDAO) Update(review *models.Review) error {
+	return dao.db.Omit("DishRatings").Save(review).Error
+}
+
+// withReviewer selects reviews with the name of their author, without exposing
+// the rest of the author's account
+func (dao *ReviewDAO) withReviewer() *gorm.DB {
+	return dao.db.Select("reviews.*, (SELECT name FROM users WHERE users.id = reviews.user_id) AS reviewer_name")
+}
+
+func (dao *ReviewDAO) GetByID(id uint) (*models.Review, error) {
+	var review models.Review
+	err := dao.withReviewer().Preload("DishRatings", func(db *gorm.DB) *gorm.DB {
+		return db.Order("dish_ratings.id")
+	}).First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByIDForUpdate loads the review without associations and locks its row until
+// the surrounding transaction ends
+func (dao *ReviewDAO) GetByIDForUpdate(id uint) (*models.Review, error) {
+	var review models.Review
+	err := dao.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (dao *ReviewDAO) GetByOrderID(orderID uint) (*models.Review, error) {
+	var review models.Review
+	err := dao.withReviewer().Preload("DishRatings", func(db *gorm.DB) *gorm.DB {
+		return db.Order("dish_ratings.id")
+	}).Where("order_id = ?", orderID).First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (dao *ReviewDAO) CountByOrderID(orderID uint) (int64, error) {
+	var count int64
+	err := dao.db.Model(&models.Review{}).Where("order_id = ?", orderID).Count(&count).Error
+	return count, err
+}
+
+var reviewSortColumns = sortColumns{
+	"id":         "id",
+	"created_at": "created_at",
+	"rating":     "rating",
+}
+
+// GetAll returns a page of reviews matching the filter, newest first by default
+func (dao *ReviewDAO) GetAll(filter models.ReviewFilter, page models.PageRequest) (*models.Page[models.Review], error) {
+	query := dao.withReviewer().Model(&models.Review{}).Preload("DishRatings")
+	if filter.RestaurantID != 0 {
+		query = query.Where("restaurant_id = ?", filter.RestaurantID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	return findPage[models.Review](query, page, reviewSortColumns, "-created_at")
+}
+
+// RefreshRatings works out the rating and review count of a restaurant and the
+// rating of its dishes again from the reviews that are not hidden
+func (dao *ReviewDAO) RefreshRatings(restaurantID uint) error {
+	err := dao.db.Exec(`
+		UPDATE restaurants
+		SET rating = COALESCE((SELECT ROUND(AVG(rating), 2) FROM reviews
+				WHERE restaurant_id = @restaurant AND status <> @hidden), 0),
+			review_count = (SELECT COUNT(*) FROM reviews
+				WHERE restaurant_id = @restaurant AND status <> @hidden)
+		WHERE id = @restaurant`,
+		sql.Named("restaurant", restaurantID), sql.Named("hidden", models.ReviewStatusHidden)).Error
+	if err != nil {
+		return err
+	}
+	return dao.db.Exec(`
+		UPDATE dishes
+		SET rating = COALESCE((SELECT ROUND(AVG(dish_ratings.rating), 2) FROM dish_ratings
+				JOIN reviews ON reviews.id = dish_ratings.review_id
+				WHERE dish_ratings.dish_id = dishes.id AND reviews.status <> @hidden), 0),
+			rating_count = (SELECT COUNT(*) FROM dish_ratings
+				JOIN reviews ON reviews.id = dish_ratings.review_id
+				WHERE dish_ratings.dish_id = dishes.id AND reviews.status <> @hidden)
+		WHERE restaurant_id = @restaurant`,
+		sql.Named("restaurant", restaurantID), sql.Named("hidden", models.ReviewStatusHidden)).Error
+}
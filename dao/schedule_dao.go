@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"time"
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleDAO stores the opening hours and closures of restaurants
+type ScheduleDAO struct {
+	db *gorm.DB
+}
+
+func NewScheduleDAO(db *gorm.DB) *ScheduleDAO {
+	return &ScheduleDAO{db: db}
+}
+
+// ReplaceOpeningHours replaces the whole weekly schedule of a restaurant
+func (dao *ScheduleDAO) ReplaceOpeningHours(restaurantID uint, hours []models.OpeningHours) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("restaurant_id = ?", restaurantID).Delete(&models.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		if len(hours) == 0 {
+			return nil
+		}
+		return tx.Create(&hours).Error
+	})
+}
+
+// GetOpeningHours returns the opening hours of the given restaurants ordered by day and time
+func (dao *ScheduleDAO) GetOpeningHours(restaurantIDs ...uint) ([]models.OpeningHours, error) {
+	var hours []models.OpeningHours
+	err := dao.db.Where("restaurant_id IN ?", restaurantIDs).
+		Order("restaurant_id, day_of_week, opens_at").
+		Find(&hours).Error
+	return hours, err
+}
+
+func (dao *ScheduleDAO) CreateClosure(closure *models.RestaurantClosure) error {
+	return dao.db.Create(closure).Error
+}
+
+func (dao *ScheduleDAO) GetClosureByID(id uint) (*models.RestaurantClosure, error) {
+	var closure models.RestaurantClosure
+	err := dao.db.First(&closure, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &closure, nil
+}
+
+// GetClosures returns the closures of the given restaurants that have not ended by
+// the given time, earliest first
+func (dao *ScheduleDAO) GetClosures(after time.Time, restaurantIDs ...uint) ([]models.RestaurantClosure, error) {
+	var closures []models.RestaurantClosure
+	err := dao.db.Where("restaurant_id IN ? AND ends_at > ?", restaurantIDs, after).
+		Order("starts_at, id").
+		Find(&closures).Error
+	return closures, err
+}
+
+func (dao *ScheduleDAO) DeleteClosure(id uint) error {
+	return dao.db.Delete(&models.RestaurantClosure{}, id).Error
+}
@@ -0,0 +1,73 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+// minWordSimilarity is the pg_trgm word similarity above which a misspelled
+// name still counts as a match
+const minWordSimilarity = 0.4
+
+// headlineOptions configures the <mark> highlighting of ts_headline. The text is
+// HTML-escaped first, so the <mark> tags are the only markup in a highlight.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=10, HighlightAll=false"
+
+type SearchDAO struct {
+	db *gorm.DB
+}
+
+func NewSearchDAO(db *gorm.DB) *SearchDAO {
+	return &SearchDAO{db: db}
+}
+
+// SearchRestaurants ranks active restaurants by full-text match of tsQuery with
+// a trigram fallback on the name for misspelled terms
+func (dao *SearchDAO) SearchRestaurants(term, tsQuery string, limit int) ([]models.RestaurantSearchResult, error) {
+	var results []models.RestaurantSearchResult
+	err := dao.db.Raw(`
+		SELECT r.id, r.name, r.cuisine, r.city, r.logo_url,
+			ts_rank(r.search_vector, q) + word_similarity(@term, r.name) AS rank,
+			ts_headline('simple', html_escape(r.name), q, @options) AS name_highlight,
+			ts_headline('simple', html_escape(coalesce(r.description, '')), q, @options) AS snippet
+		FROM restaurants r, to_tsquery('simple', @query) q
+		WHERE r.is_active
+			AND (r.search_vector @@ q OR word_similarity(@term, r.name) >= @similarity)
+		ORDER BY rank DESC, r.id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"term":       term,
+			"query":      tsQuery,
+			"options":    headlineOptions,
+			"similarity": minWordSimilarity,
+			"limit":      limit,
+		}).Scan(&results).Error
+	return results, err
+}
+
+// SearchDishes ranks available dishes of active restaurants by full-text match
+// of tsQuery with a trigram fallback on the name for misspelled terms
+func (dao *SearchDAO) SearchDishes(term, tsQuery string, limit int) ([]models.DishSearchResult, error) {
+	var results []models.DishSearchResult
+	err := dao.db.Raw(`
+		SELECT d.id, d.name, d.category, d.price_amount, d.price_currency, d.image_url,
+			d.restaurant_id, r.name AS restaurant_name,
+			ts_rank(d.search_vector, q) + word_similarity(@term, d.name) AS rank,
+			ts_headline('simple', html_escape(d.name), q, @options) AS name_highlight,
+			ts_headline('simple', html_escape(coalesce(d.description, '')), q, @options) AS snippet
+		FROM dishes d
+		JOIN restaurants r ON r.id = d.restaurant_id, to_tsquery('simple', @query) q
+		WHERE d.is_available AND r.is_active
+			AND (d.search_vector @@ q OR word_similarity(@term, d.name) >= @similarity)
+		ORDER BY rank DESC, d.id
+		LIMIT @limit`,
+		map[string]interface{}{
+			"term":       term,
+			"query":      tsQuery,
+			"options":    headlineOptions,
+			"similarity": minWordSimilarity,
+			"limit":      limit,
+		}).Scan(&results).Error
+	return results, err
+}
@@ -0,0 +1,59 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+)
+
+// UnitOfWork runs a group of DAO operations inside a single database transaction
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Tx exposes DAOs bound to the same transaction
+type Tx struct {
+	Orders      *OrderDAO
+	Dishes      *DishDAO
+	Restaurants *RestaurantDAO
+	Members     *RestaurantMemberDAO
+	Users       *UserDAO
+	Addresses   *UserAddressDAO
+	Schedules   *ScheduleDAO
+	Modifiers   *ModifierDAO
+	Menus       *MenuDAO
+	Payments    *PaymentDAO
+	Refunds     *RefundDAO
+	Promotions  *PromotionDAO
+	Drivers     *DriverDAO
+	Offers      *DeliveryOfferDAO
+	Reviews     *ReviewDAO
+	Carts       *CartDAO
+}
+
+// Do runs fn inside a transaction. The transaction is committed when fn returns nil
+// and rolled back otherwise.
+func (u *UnitOfWork) Do(fn func(tx *Tx) error) error {
+	return u.db.Transaction(func(db *gorm.DB) error {
+		return fn(&Tx{
+			Orders:      NewOrderDAO(db),
+			Dishes:      NewDishDAO(db),
+			Restaurants: NewRestaurantDAO(db),
+			Members:     NewRestaurantMemberDAO(db),
+			Users:       NewUserDAO(db),
+			Addresses:   NewUserAddressDAO(db),
+			Schedules:   NewScheduleDAO(db),
+			Modifiers:   NewModifierDAO(db),
+			Menus:       NewMenuDAO(db),
+			Payments:    NewPaymentDAO(db),
+			Refunds:     NewRefundDAO(db),
+			Promotions:  NewPromotionDAO(db),
+			Drivers:     NewDriverDAO(db),
+			Offers:      NewDeliveryOfferDAO(db),
+			Reviews:     NewReviewDAO(db),
+			Carts:       NewCartDAO(db),
+		})
+	})
+}
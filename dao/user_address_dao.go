@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"tumdum_backend/models"
+
+	"gorm.io/gorm"
+)
+
+type UserAddressDAO struct {
+	db *gorm.DB
+}
+
+func NewUserAddressDAO(db *gorm.DB) *UserAddressDAO {
+	return &UserAddressDAO{db: db}
+}
+
+// Create saves a new address. A default address replaces the user's previous default.
+func (dao *UserAddressDAO) Create(address *models.UserAddress) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAddress(tx, address); err != nil {
+			return err
+		}
+		return tx.Create(address).Error
+	})
+}
+
+func (dao *UserAddressDAO) GetByID(id uint) (*models.UserAddress, error) {
+	var address models.UserAddress
+	err := dao.db.First(&address, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (dao *UserAddressDAO) GetByUserID(userID uint) ([]models.UserAddress, error) {
+	var addresses []models.UserAddress
+	err := dao.db.Where("user_id = ?", userID).
+		Order("is_default DESC, id").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// GetDefault returns the user's default address
+func (dao *UserAddressDAO) GetDefault(userID uint) (*models.UserAddress, error) {
+	var address models.UserAddress
+	err := dao.db.Where("user_id = ? AND is_default", userID).First(&address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// Update saves the address. A default address replaces the user's previous default.
+func (dao *UserAddressDAO) Update(address *models.UserAddress) error {
+	return dao.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAddress(tx, address); err != nil {
+			return err
+		}
+		return tx.Save(address).Error
+	})
+}
+
+func (dao *UserAddressDAO) Delete(id uint) error {
+	return dao.db.Delete(&models.UserAddress{}, id).Error
+}
+
+// clearDefaultAddress unsets the user's other default address when address becomes the default
+func clearDefaultAddress(tx *gorm.DB, address *models.UserAddress) error {
+	if !address.IsDefault {
+		return nil
+	}
+	return tx.Model(&models.UserAddress{}).
+		Where("user_id = ? AND is_default AND id <> ?", address.UserID, address.ID).
+		Update("is_default", false).Error
+}
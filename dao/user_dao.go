@@ -34,6 +34,16 @@ func (dao *UserDAO) GetByID(id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// GetRole returns the current role of a user
+func (dao *UserDAO) GetRole(id uint) (models.UserRole, error) {
+	var user models.User
+	err := dao.db.Select("id", "role").First(&user, id).Error
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
 func (dao *UserDAO) GetByEmail(email string) (*models.User, error) {
 	var user models.User
 	err := dao.db.Where("email = ?", email).First(&user).Error
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS order_status_events;
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS dishes;
+DROP TABLE IF EXISTS restaurant_members;
+DROP TABLE IF EXISTS restaurants;
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,187 @@
+-- Shared trigger function keeping updated_at current
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Users
+CREATE TABLE users (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    email VARCHAR(100) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'CUSTOMER',
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    phone VARCHAR(20) NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    city VARCHAR(50) NOT NULL DEFAULT '',
+    state VARCHAR(50) NOT NULL DEFAULT '',
+    country VARCHAR(50) NOT NULL DEFAULT '',
+    postal_code VARCHAR(20) NOT NULL DEFAULT '',
+    CONSTRAINT users_email_unique UNIQUE (email),
+    CONSTRAINT users_role_check CHECK (role IN ('CUSTOMER', 'OWNER', 'ADMIN'))
+);
+
+CREATE INDEX idx_users_role ON users(role);
+
+CREATE TRIGGER update_users_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Restaurants
+CREATE TABLE restaurants (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    email VARCHAR(100) NOT NULL,
+    phone VARCHAR(20) NOT NULL,
+    address TEXT NOT NULL,
+    city VARCHAR(50) NOT NULL,
+    state VARCHAR(50) NOT NULL,
+    country VARCHAR(50) NOT NULL,
+    postal_code VARCHAR(20) NOT NULL,
+    cuisine VARCHAR(50) NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    rating DECIMAL(3,2) DEFAULT 0.0,
+    is_active BOOLEAN DEFAULT true,
+    logo_url TEXT,
+    cover_image_url TEXT,
+    CONSTRAINT restaurants_rating_check CHECK (rating >= 0 AND rating <= 5),
+    CONSTRAINT restaurants_email_unique UNIQUE (email),
+    CONSTRAINT restaurants_phone_unique UNIQUE (phone)
+);
+
+CREATE INDEX idx_restaurants_name ON restaurants(name);
+CREATE INDEX idx_restaurants_cuisine ON restaurants(cuisine);
+CREATE INDEX idx_restaurants_is_active ON restaurants(is_active);
+CREATE INDEX idx_restaurants_city ON restaurants(city);
+
+CREATE TRIGGER update_restaurants_updated_at
+    BEFORE UPDATE ON restaurants
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Restaurant members
+CREATE TABLE restaurant_members (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    restaurant_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by_id INTEGER,
+    CONSTRAINT restaurant_members_role_check CHECK (role IN ('OWNER', 'MANAGER', 'KITCHEN_STAFF')),
+    CONSTRAINT fk_restaurant_members_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_restaurant_members_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_restaurant_members_invited_by FOREIGN KEY (invited_by_id)
+        REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_restaurant_members_restaurant_user ON restaurant_members(restaurant_id, user_id);
+CREATE INDEX idx_restaurant_members_user_id ON restaurant_members(user_id);
+
+CREATE TRIGGER update_restaurant_members_updated_at
+    BEFORE UPDATE ON restaurant_members
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Dishes
+CREATE TABLE dishes (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    restaurant_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    price_amount BIGINT NOT NULL,
+    price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    is_available BOOLEAN DEFAULT true,
+    image_url TEXT,
+    CONSTRAINT dishes_price_amount_check CHECK (price_amount > 0),
+    CONSTRAINT fk_dishes_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_dishes_restaurant_id ON dishes(restaurant_id);
+CREATE INDEX idx_dishes_name ON dishes(name);
+CREATE INDEX idx_dishes_category ON dishes(category);
+CREATE INDEX idx_dishes_is_available ON dishes(is_available);
+
+CREATE TRIGGER update_dishes_updated_at
+    BEFORE UPDATE ON dishes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Orders
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER,
+    restaurant_id INTEGER,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    total_amount BIGINT NOT NULL,
+    total_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    CONSTRAINT orders_total_amount_check CHECK (total_amount > 0),
+    CONSTRAINT fk_orders_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_orders_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+CREATE INDEX idx_orders_restaurant_id ON orders(restaurant_id);
+CREATE INDEX idx_orders_status ON orders(status);
+CREATE INDEX idx_orders_created_at ON orders(created_at);
+
+CREATE TRIGGER update_orders_updated_at
+    BEFORE UPDATE ON orders
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Order items
+CREATE TABLE order_items (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    dish_id INTEGER,
+    quantity INTEGER NOT NULL,
+    price_amount BIGINT NOT NULL,
+    price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    CONSTRAINT order_items_quantity_check CHECK (quantity > 0),
+    CONSTRAINT order_items_price_amount_check CHECK (price_amount > 0),
+    CONSTRAINT fk_order_items_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_items_dish FOREIGN KEY (dish_id)
+        REFERENCES dishes(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_items_order_id ON order_items(order_id);
+
+-- Order status history
+CREATE TABLE order_status_events (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    actor_id INTEGER,
+    reason TEXT,
+    CONSTRAINT fk_order_status_events_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_status_events_actor FOREIGN KEY (actor_id)
+        REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_status_events_order_id ON order_status_events(order_id);
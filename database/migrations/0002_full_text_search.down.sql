@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_dishes_name_trgm;
+DROP INDEX IF EXISTS idx_dishes_search_vector;
+ALTER TABLE dishes DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_restaurants_name_trgm;
+DROP INDEX IF EXISTS idx_restaurants_search_vector;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS search_vector;
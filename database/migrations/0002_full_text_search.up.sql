@@ -0,0 +1,22 @@
+-- Trigram matching is used for typo-tolerant search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Restaurants are searchable by name, cuisine and description
+ALTER TABLE restaurants ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(cuisine, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_restaurants_search_vector ON restaurants USING GIN (search_vector);
+CREATE INDEX idx_restaurants_name_trgm ON restaurants USING GIN (name gin_trgm_ops);
+
+-- Dishes are searchable by name, category and description
+ALTER TABLE dishes ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(category, '')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_dishes_search_vector ON dishes USING GIN (search_vector);
+CREATE INDEX idx_dishes_name_trgm ON dishes USING GIN (name gin_trgm_ops);
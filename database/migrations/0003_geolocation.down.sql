@@ -0,0 +1,18 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS delivery_distance_km,
+    DROP COLUMN IF EXISTS delivery_longitude,
+    DROP COLUMN IF EXISTS delivery_latitude,
+    DROP COLUMN IF EXISTS delivery_address,
+    DROP COLUMN IF EXISTS delivery_address_id;
+
+DROP TABLE IF EXISTS user_addresses;
+
+DROP INDEX IF EXISTS idx_restaurants_location;
+ALTER TABLE restaurants
+    DROP COLUMN IF EXISTS delivery_radius_km,
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS longitude,
+    DROP COLUMN IF EXISTS latitude;
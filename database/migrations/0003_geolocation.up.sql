@@ -0,0 +1,49 @@
+-- Coordinates are stored in decimal degrees (WGS 84)
+ALTER TABLE users
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION;
+
+ALTER TABLE restaurants
+    ADD COLUMN latitude DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD COLUMN delivery_radius_km DOUBLE PRECISION NOT NULL DEFAULT 5,
+    ADD CONSTRAINT restaurants_delivery_radius_check CHECK (delivery_radius_km > 0);
+
+CREATE INDEX idx_restaurants_location ON restaurants(latitude, longitude);
+
+-- Saved delivery addresses
+CREATE TABLE user_addresses (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    label VARCHAR(50) NOT NULL DEFAULT '',
+    address TEXT NOT NULL,
+    city VARCHAR(50) NOT NULL DEFAULT '',
+    state VARCHAR(50) NOT NULL DEFAULT '',
+    country VARCHAR(50) NOT NULL DEFAULT '',
+    postal_code VARCHAR(20) NOT NULL DEFAULT '',
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    CONSTRAINT fk_user_addresses_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_user_addresses_user_id ON user_addresses(user_id);
+CREATE UNIQUE INDEX idx_user_addresses_default ON user_addresses(user_id) WHERE is_default;
+
+CREATE TRIGGER update_user_addresses_updated_at
+    BEFORE UPDATE ON user_addresses
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Where an order is delivered to
+ALTER TABLE orders
+    ADD COLUMN delivery_address_id INTEGER,
+    ADD COLUMN delivery_address TEXT NOT NULL DEFAULT '',
+    ADD COLUMN delivery_latitude DOUBLE PRECISION,
+    ADD COLUMN delivery_longitude DOUBLE PRECISION,
+    ADD COLUMN delivery_distance_km DOUBLE PRECISION,
+    ADD CONSTRAINT fk_orders_delivery_address FOREIGN KEY (delivery_address_id)
+        REFERENCES user_addresses(id) ON DELETE SET NULL;
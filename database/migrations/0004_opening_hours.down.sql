@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS restaurant_closures;
+DROP TABLE IF EXISTS opening_hours;
+ALTER TABLE restaurants DROP COLUMN IF EXISTS time_zone;
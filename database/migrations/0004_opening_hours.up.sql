@@ -0,0 +1,46 @@
+-- Opening hours are evaluated in the restaurant's IANA time zone
+ALTER TABLE restaurants ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Weekly opening hours, several intervals per day
+CREATE TABLE opening_hours (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    restaurant_id INTEGER NOT NULL,
+    day_of_week SMALLINT NOT NULL,
+    opens_at VARCHAR(8) NOT NULL,
+    closes_at VARCHAR(8) NOT NULL,
+    CONSTRAINT opening_hours_day_of_week_check CHECK (day_of_week BETWEEN 0 AND 6),
+    CONSTRAINT opening_hours_opens_at_check CHECK (opens_at ~ '^([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$'),
+    CONSTRAINT opening_hours_closes_at_check CHECK (closes_at ~ '^([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]$'),
+    CONSTRAINT fk_opening_hours_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_opening_hours_restaurant_id ON opening_hours(restaurant_id);
+
+CREATE TRIGGER update_opening_hours_updated_at
+    BEFORE UPDATE ON opening_hours
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Holidays and other exceptional closures
+CREATE TABLE restaurant_closures (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    restaurant_id INTEGER NOT NULL,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    CONSTRAINT restaurant_closures_range_check CHECK (ends_at > starts_at),
+    CONSTRAINT fk_restaurant_closures_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_restaurant_closures_restaurant_ends_at ON restaurant_closures(restaurant_id, ends_at);
+
+CREATE TRIGGER update_restaurant_closures_updated_at
+    BEFORE UPDATE ON restaurant_closures
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
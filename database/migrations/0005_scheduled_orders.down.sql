@@ -0,0 +1,5 @@
+ALTER TABLE restaurants DROP COLUMN IF EXISTS slot_capacity;
+
+DROP INDEX IF EXISTS idx_orders_scheduled_release;
+DROP INDEX IF EXISTS idx_orders_restaurant_scheduled_for;
+ALTER TABLE orders DROP COLUMN IF EXISTS scheduled_for;
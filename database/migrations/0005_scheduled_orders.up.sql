@@ -0,0 +1,11 @@
+-- Orders may be placed ahead of time for a future delivery slot
+ALTER TABLE orders ADD COLUMN scheduled_for TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_orders_restaurant_scheduled_for ON orders(restaurant_id, scheduled_for)
+    WHERE scheduled_for IS NOT NULL;
+CREATE INDEX idx_orders_scheduled_release ON orders(scheduled_for)
+    WHERE status = 'SCHEDULED';
+
+-- Maximum number of scheduled orders per delivery slot, 0 is unlimited
+ALTER TABLE restaurants ADD COLUMN slot_capacity INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT restaurants_slot_capacity_check CHECK (slot_capacity >= 0);
@@ -0,0 +1,40 @@
+-- Server-side shopping carts, one per user
+CREATE TABLE carts (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    restaurant_id INTEGER,
+    CONSTRAINT fk_carts_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_carts_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_carts_user_id ON carts(user_id);
+
+CREATE TRIGGER update_carts_updated_at
+    BEFORE UPDATE ON carts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE cart_items (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    cart_id INTEGER NOT NULL,
+    dish_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    CONSTRAINT cart_items_quantity_check CHECK (quantity > 0),
+    CONSTRAINT fk_cart_items_cart FOREIGN KEY (cart_id)
+        REFERENCES carts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_items_dish FOREIGN KEY (dish_id)
+        REFERENCES dishes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_cart_items_cart_id ON cart_items(cart_id);
+
+CREATE TRIGGER update_cart_items_updated_at
+    BEFORE UPDATE ON cart_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS line_total_currency,
+    DROP COLUMN IF EXISTS line_total_amount;
+
+DROP TABLE IF EXISTS cart_item_modifiers;
+DROP TABLE IF EXISTS order_item_modifiers;
+DROP TABLE IF EXISTS modifier_options;
+DROP TABLE IF EXISTS modifier_groups;
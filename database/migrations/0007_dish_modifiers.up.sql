@@ -0,0 +1,81 @@
+-- Modifier groups and their priced options offered with a dish
+CREATE TABLE modifier_groups (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    dish_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    is_required BOOLEAN NOT NULL DEFAULT false,
+    min_selections INTEGER NOT NULL DEFAULT 0,
+    max_selections INTEGER NOT NULL DEFAULT 0,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT modifier_groups_min_selections_check CHECK (min_selections >= 0),
+    CONSTRAINT modifier_groups_max_selections_check CHECK (max_selections = 0 OR max_selections >= min_selections),
+    CONSTRAINT fk_modifier_groups_dish FOREIGN KEY (dish_id)
+        REFERENCES dishes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_modifier_groups_dish_id ON modifier_groups(dish_id);
+
+CREATE TRIGGER update_modifier_groups_updated_at
+    BEFORE UPDATE ON modifier_groups
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE modifier_options (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    modifier_group_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    price_amount BIGINT NOT NULL DEFAULT 0,
+    price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    is_available BOOLEAN DEFAULT true,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT modifier_options_price_amount_check CHECK (price_amount >= 0),
+    CONSTRAINT fk_modifier_options_group FOREIGN KEY (modifier_group_id)
+        REFERENCES modifier_groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_modifier_options_modifier_group_id ON modifier_options(modifier_group_id);
+
+CREATE TRIGGER update_modifier_options_updated_at
+    BEFORE UPDATE ON modifier_options
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Options selected for an order item, with the name and price at the time of ordering
+CREATE TABLE order_item_modifiers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_item_id INTEGER NOT NULL,
+    modifier_option_id INTEGER,
+    group_name VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    price_amount BIGINT NOT NULL DEFAULT 0,
+    price_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    CONSTRAINT fk_order_item_modifiers_order_item FOREIGN KEY (order_item_id)
+        REFERENCES order_items(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_item_modifiers_option FOREIGN KEY (modifier_option_id)
+        REFERENCES modifier_options(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_item_modifiers_order_item_id ON order_item_modifiers(order_item_id);
+
+-- Options selected for a cart item
+CREATE TABLE cart_item_modifiers (
+    id SERIAL PRIMARY KEY,
+    cart_item_id INTEGER NOT NULL,
+    modifier_option_id INTEGER NOT NULL,
+    CONSTRAINT fk_cart_item_modifiers_cart_item FOREIGN KEY (cart_item_id)
+        REFERENCES cart_items(id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_item_modifiers_option FOREIGN KEY (modifier_option_id)
+        REFERENCES modifier_options(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_cart_item_modifiers_cart_item_id ON cart_item_modifiers(cart_item_id);
+
+-- Line totals include the modifier prices; existing items have none
+ALTER TABLE order_items ADD COLUMN line_total_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN line_total_currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE order_items SET line_total_amount = price_amount * quantity, line_total_currency = price_currency;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_dishes_menu_category_id;
+ALTER TABLE dishes DROP CONSTRAINT IF EXISTS fk_dishes_menu_category,
+    DROP COLUMN IF EXISTS display_order,
+    DROP COLUMN IF EXISTS menu_category_id;
+
+DROP TABLE IF EXISTS menu_categories;
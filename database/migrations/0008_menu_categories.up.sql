@@ -0,0 +1,45 @@
+-- Menu categories of a restaurant with display order and an optional time-of-day window
+CREATE TABLE menu_categories (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    restaurant_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    available_from VARCHAR(8),
+    available_until VARCHAR(8),
+    is_active BOOLEAN DEFAULT true,
+    CONSTRAINT menu_categories_window_check CHECK ((available_from IS NULL) = (available_until IS NULL)),
+    CONSTRAINT fk_menu_categories_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_menu_categories_restaurant_id ON menu_categories(restaurant_id);
+
+CREATE TRIGGER update_menu_categories_updated_at
+    BEFORE UPDATE ON menu_categories
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE dishes ADD COLUMN menu_category_id INTEGER,
+    ADD COLUMN display_order INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT fk_dishes_menu_category FOREIGN KEY (menu_category_id)
+        REFERENCES menu_categories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_dishes_menu_category_id ON dishes(menu_category_id);
+
+-- Turn the free-text categories of existing dishes into menu categories
+INSERT INTO menu_categories (restaurant_id, name, display_order)
+SELECT restaurant_id, category, ROW_NUMBER() OVER (PARTITION BY restaurant_id ORDER BY category) - 1
+FROM (
+    SELECT DISTINCT restaurant_id, TRIM(category) AS category
+    FROM dishes
+    WHERE TRIM(category) <> ''
+) AS existing;
+
+UPDATE dishes
+SET menu_category_id = menu_categories.id
+FROM menu_categories
+WHERE menu_categories.restaurant_id = dishes.restaurant_id
+    AND menu_categories.name = TRIM(dishes.category);
@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS stock_reserved_at;
+
+DROP INDEX IF EXISTS idx_dishes_daily_stock;
+ALTER TABLE dishes DROP COLUMN IF EXISTS stock_reset_at,
+    DROP COLUMN IF EXISTS stock_remaining,
+    DROP COLUMN IF EXISTS daily_stock;
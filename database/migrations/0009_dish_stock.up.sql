@@ -0,0 +1,11 @@
+-- Optional daily stock of dishes, reset every day in the restaurant's time zone
+ALTER TABLE dishes ADD COLUMN daily_stock INTEGER,
+    ADD COLUMN stock_remaining INTEGER,
+    ADD COLUMN stock_reset_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT dishes_daily_stock_check CHECK (daily_stock >= 0),
+    ADD CONSTRAINT dishes_stock_remaining_check CHECK (stock_remaining >= 0);
+
+CREATE INDEX idx_dishes_daily_stock ON dishes(restaurant_id) WHERE daily_stock IS NOT NULL;
+
+-- Set while an order holds portions of the daily stock, so cancelling it can return them
+ALTER TABLE orders ADD COLUMN stock_reserved_at TIMESTAMP WITH TIME ZONE;
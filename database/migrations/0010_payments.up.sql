@@ -0,0 +1,29 @@
+-- Payments of orders through a payment provider
+CREATE TABLE payments (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_reference VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    amount_amount BIGINT NOT NULL,
+    amount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    captured_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    refunded_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    failure_reason TEXT,
+    CONSTRAINT payments_amount_check CHECK (amount_amount >= 0),
+    CONSTRAINT fk_payments_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_payments_order_id ON payments(order_id);
+CREATE UNIQUE INDEX idx_payments_provider_reference ON payments(provider, provider_reference)
+    WHERE provider_reference <> '';
+
+CREATE TRIGGER update_payments_updated_at
+    BEFORE UPDATE ON payments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+ALTER TABLE order_status_events DROP COLUMN IF EXISTS refund_id;
+
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS refunded_quantity;
@@ -0,0 +1,63 @@
+-- Refunded quantities stay on the order item but no longer count towards the order total
+ALTER TABLE order_items ADD COLUMN refunded_quantity INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT order_items_refunded_quantity_check
+        CHECK (refunded_quantity >= 0 AND refunded_quantity <= quantity);
+
+-- Requested, approved and rejected refunds of order items
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    requested_by INTEGER,
+    reason_code VARCHAR(30) NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL,
+    amount_amount BIGINT NOT NULL,
+    amount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    reviewed_by INTEGER,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    review_note TEXT,
+    CONSTRAINT refunds_amount_check CHECK (amount_amount >= 0),
+    CONSTRAINT fk_refunds_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_refunds_requested_by FOREIGN KEY (requested_by)
+        REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_refunds_reviewed_by FOREIGN KEY (reviewed_by)
+        REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
+
+CREATE TRIGGER update_refunds_updated_at
+    BEFORE UPDATE ON refunds
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE refund_items (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    refund_id INTEGER NOT NULL,
+    order_item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    amount_amount BIGINT NOT NULL,
+    amount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    CONSTRAINT refund_items_quantity_check CHECK (quantity > 0),
+    CONSTRAINT fk_refund_items_refund FOREIGN KEY (refund_id)
+        REFERENCES refunds(id) ON DELETE CASCADE,
+    CONSTRAINT fk_refund_items_order_item FOREIGN KEY (order_item_id)
+        REFERENCES order_items(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_refund_items_refund_id ON refund_items(refund_id);
+
+CREATE TRIGGER update_refund_items_updated_at
+    BEFORE UPDATE ON refund_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Refund requests and reviews appear in the order timeline
+ALTER TABLE order_status_events ADD COLUMN refund_id INTEGER,
+    ADD CONSTRAINT fk_order_status_events_refund FOREIGN KEY (refund_id)
+        REFERENCES refunds(id) ON DELETE SET NULL;
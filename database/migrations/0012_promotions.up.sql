@@ -0,0 +1,85 @@
+-- Promo codes, platform-wide or limited to one restaurant
+CREATE TABLE promotions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL,
+    percent_off INTEGER NOT NULL DEFAULT 0,
+    amount_off_amount BIGINT NOT NULL DEFAULT 0,
+    amount_off_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    restaurant_id INTEGER,
+    min_order_amount BIGINT NOT NULL DEFAULT 0,
+    min_order_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    max_redemptions INTEGER,
+    max_redemptions_per_user INTEGER,
+    starts_at TIMESTAMP WITH TIME ZONE,
+    ends_at TIMESTAMP WITH TIME ZONE,
+    first_order_only BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    CONSTRAINT promotions_code_key UNIQUE (code),
+    CONSTRAINT promotions_percent_off_check CHECK (percent_off BETWEEN 0 AND 100),
+    CONSTRAINT promotions_amount_off_check CHECK (amount_off_amount >= 0),
+    CONSTRAINT promotions_min_order_check CHECK (min_order_amount >= 0),
+    CONSTRAINT promotions_max_redemptions_check CHECK (max_redemptions > 0),
+    CONSTRAINT promotions_max_redemptions_per_user_check CHECK (max_redemptions_per_user > 0),
+    CONSTRAINT fk_promotions_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_promotions_restaurant_id ON promotions(restaurant_id);
+
+CREATE TRIGGER update_promotions_updated_at
+    BEFORE UPDATE ON promotions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Uses of promotions; removed again when the order is cancelled
+CREATE TABLE promotion_redemptions (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    promotion_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    order_id INTEGER NOT NULL,
+    discount_amount BIGINT NOT NULL,
+    discount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    CONSTRAINT promotion_redemptions_order_key UNIQUE (order_id),
+    CONSTRAINT fk_promotion_redemptions_promotion FOREIGN KEY (promotion_id)
+        REFERENCES promotions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_promotion_redemptions_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_promotion_redemptions_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_promotion_redemptions_promotion_user ON promotion_redemptions(promotion_id, user_id);
+
+-- Discount lines of orders
+CREATE TABLE order_discounts (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    promotion_id INTEGER,
+    code VARCHAR(50) NOT NULL DEFAULT '',
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL,
+    percent_off INTEGER NOT NULL DEFAULT 0,
+    amount_off_amount BIGINT NOT NULL DEFAULT 0,
+    amount_off_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    amount_amount BIGINT NOT NULL,
+    amount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    CONSTRAINT order_discounts_amount_check CHECK (amount_amount >= 0),
+    CONSTRAINT fk_order_discounts_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_discounts_promotion FOREIGN KEY (promotion_id)
+        REFERENCES promotions(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_order_discounts_order_id ON order_discounts(order_id);
+
+CREATE TRIGGER update_order_discounts_updated_at
+    BEFORE UPDATE ON order_discounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,24 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_tip_check,
+    DROP COLUMN IF EXISTS tip_currency,
+    DROP COLUMN IF EXISTS tip_amount,
+    DROP COLUMN IF EXISTS packaging_fee_currency,
+    DROP COLUMN IF EXISTS packaging_fee_amount,
+    DROP COLUMN IF EXISTS delivery_fee_currency,
+    DROP COLUMN IF EXISTS delivery_fee_amount,
+    DROP COLUMN IF EXISTS tax_currency,
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS tax_rate,
+    DROP COLUMN IF EXISTS discount_currency,
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS subtotal_currency,
+    DROP COLUMN IF EXISTS subtotal_amount;
+
+ALTER TABLE restaurants DROP CONSTRAINT IF EXISTS restaurants_packaging_fee_check,
+    DROP CONSTRAINT IF EXISTS restaurants_delivery_fee_per_km_check,
+    DROP CONSTRAINT IF EXISTS restaurants_delivery_fee_check,
+    DROP COLUMN IF EXISTS packaging_fee_currency,
+    DROP COLUMN IF EXISTS packaging_fee_amount,
+    DROP COLUMN IF EXISTS delivery_fee_per_km_currency,
+    DROP COLUMN IF EXISTS delivery_fee_per_km_amount,
+    DROP COLUMN IF EXISTS delivery_fee_currency,
+    DROP COLUMN IF EXISTS delivery_fee_amount;
@@ -0,0 +1,41 @@
+-- Fees charged by restaurants on top of the dish prices
+ALTER TABLE restaurants ADD COLUMN delivery_fee_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN delivery_fee_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN delivery_fee_per_km_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN delivery_fee_per_km_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN packaging_fee_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN packaging_fee_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD CONSTRAINT restaurants_delivery_fee_check CHECK (delivery_fee_amount >= 0),
+    ADD CONSTRAINT restaurants_delivery_fee_per_km_check CHECK (delivery_fee_per_km_amount >= 0),
+    ADD CONSTRAINT restaurants_packaging_fee_check CHECK (packaging_fee_amount >= 0);
+
+UPDATE restaurants SET delivery_fee_currency = currency,
+    delivery_fee_per_km_currency = currency,
+    packaging_fee_currency = currency;
+
+-- Price breakdown of orders; total_amount stays the grand total
+ALTER TABLE orders ADD COLUMN subtotal_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN subtotal_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN discount_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN tax_rate NUMERIC(6,3) NOT NULL DEFAULT 0,
+    ADD COLUMN tax_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN tax_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN delivery_fee_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN delivery_fee_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN packaging_fee_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN packaging_fee_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD COLUMN tip_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN tip_currency CHAR(3) NOT NULL DEFAULT 'USD',
+    ADD CONSTRAINT orders_tip_check CHECK (tip_amount >= 0);
+
+-- Existing orders only had items and discounts
+UPDATE orders SET discount_amount = COALESCE(
+        (SELECT SUM(amount_amount) FROM order_discounts WHERE order_discounts.order_id = orders.id), 0),
+    subtotal_currency = total_currency,
+    discount_currency = total_currency,
+    tax_currency = total_currency,
+    delivery_fee_currency = total_currency,
+    packaging_fee_currency = total_currency,
+    tip_currency = total_currency;
+UPDATE orders SET subtotal_amount = total_amount + discount_amount;
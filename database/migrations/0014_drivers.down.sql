@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS driver_locations;
+DROP TABLE IF EXISTS delivery_offers;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS driver_id;
+
+DROP TABLE IF EXISTS drivers;
+
+-- Orders still on the road fall back to ready, and drivers become customers
+UPDATE orders SET status = 'READY' WHERE status IN ('PICKED_UP', 'OUT_FOR_DELIVERY');
+UPDATE users SET role = 'CUSTOMER' WHERE role = 'DRIVER';
+ALTER TABLE users DROP CONSTRAINT users_role_check,
+    ADD CONSTRAINT users_role_check CHECK (role IN ('CUSTOMER', 'OWNER', 'ADMIN'));
@@ -0,0 +1,79 @@
+-- Drivers deliver orders
+ALTER TABLE users DROP CONSTRAINT users_role_check,
+    ADD CONSTRAINT users_role_check CHECK (role IN ('CUSTOMER', 'OWNER', 'ADMIN', 'DRIVER'));
+
+-- Delivery profiles of users with the DRIVER role and where they were last seen
+CREATE TABLE drivers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER NOT NULL,
+    vehicle_type VARCHAR(50) NOT NULL,
+    license_plate VARCHAR(20),
+    is_available BOOLEAN NOT NULL DEFAULT false,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    location_updated_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT drivers_user_key UNIQUE (user_id),
+    CONSTRAINT fk_drivers_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_drivers_location ON drivers(latitude, longitude) WHERE is_available;
+
+CREATE TRIGGER update_drivers_updated_at
+    BEFORE UPDATE ON drivers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- The driver delivering an order
+ALTER TABLE orders ADD COLUMN driver_id INTEGER,
+    ADD CONSTRAINT fk_orders_driver FOREIGN KEY (driver_id)
+        REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_orders_driver_id ON orders(driver_id);
+
+-- Ready orders offered to drivers, one open offer per order and per driver at a time
+CREATE TABLE delivery_offers (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    driver_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    distance_km DOUBLE PRECISION,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT delivery_offers_status_check
+        CHECK (status IN ('OFFERED', 'ACCEPTED', 'DECLINED', 'EXPIRED')),
+    CONSTRAINT fk_delivery_offers_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_delivery_offers_driver FOREIGN KEY (driver_id)
+        REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_delivery_offers_order_id ON delivery_offers(order_id);
+CREATE INDEX idx_delivery_offers_driver_id ON delivery_offers(driver_id);
+CREATE UNIQUE INDEX idx_delivery_offers_open_order ON delivery_offers(order_id) WHERE status = 'OFFERED';
+CREATE UNIQUE INDEX idx_delivery_offers_open_driver ON delivery_offers(driver_id) WHERE status = 'OFFERED';
+
+CREATE TRIGGER update_delivery_offers_updated_at
+    BEFORE UPDATE ON delivery_offers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Location pings of drivers while delivering an order
+CREATE TABLE driver_locations (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    driver_id INTEGER NOT NULL,
+    order_id INTEGER NOT NULL,
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+    CONSTRAINT fk_driver_locations_driver FOREIGN KEY (driver_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_driver_locations_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_driver_locations_order_id ON driver_locations(order_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS estimated_delivery_at,
+    DROP COLUMN IF EXISTS estimated_ready_at;
+
+ALTER TABLE dishes DROP CONSTRAINT IF EXISTS dishes_prep_time_minutes_check,
+    DROP COLUMN IF EXISTS prep_time_minutes;
@@ -0,0 +1,7 @@
+-- Time the kitchen needs for each dish, 0 when the configured default applies
+ALTER TABLE dishes ADD COLUMN prep_time_minutes INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT dishes_prep_time_minutes_check CHECK (prep_time_minutes >= 0);
+
+-- Estimated ready and delivery times, set when an order is confirmed and revised on each status change
+ALTER TABLE orders ADD COLUMN estimated_ready_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN estimated_delivery_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS dish_ratings;
+DROP TABLE IF EXISTS reviews;
+
+ALTER TABLE dishes DROP CONSTRAINT IF EXISTS dishes_rating_check,
+    DROP COLUMN IF EXISTS rating_count,
+    DROP COLUMN IF EXISTS rating;
+
+ALTER TABLE restaurants DROP COLUMN IF EXISTS review_count;
@@ -0,0 +1,69 @@
+-- Ratings are derived from reviews that are not hidden
+ALTER TABLE restaurants ADD COLUMN review_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE dishes ADD COLUMN rating DECIMAL(3,2) DEFAULT 0.0,
+    ADD COLUMN rating_count INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT dishes_rating_check CHECK (rating >= 0 AND rating <= 5);
+
+-- Ratings given before reviews existed no longer apply
+UPDATE restaurants SET rating = 0;
+
+-- Customer reviews of delivered orders, one per order
+CREATE TABLE reviews (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    order_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    restaurant_id INTEGER NOT NULL,
+    rating INTEGER NOT NULL,
+    comment TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'PUBLISHED',
+    flagged_by INTEGER,
+    flag_reason TEXT,
+    moderated_by INTEGER,
+    moderated_at TIMESTAMP WITH TIME ZONE,
+    moderation_note TEXT,
+    reply TEXT,
+    replied_by INTEGER,
+    replied_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT reviews_order_id_key UNIQUE (order_id),
+    CONSTRAINT reviews_rating_check CHECK (rating >= 1 AND rating <= 5),
+    CONSTRAINT reviews_status_check CHECK (status IN ('PUBLISHED', 'FLAGGED', 'HIDDEN')),
+    CONSTRAINT fk_reviews_order FOREIGN KEY (order_id)
+        REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reviews_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reviews_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_reviews_flagged_by FOREIGN KEY (flagged_by)
+        REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_reviews_moderated_by FOREIGN KEY (moderated_by)
+        REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_reviews_replied_by FOREIGN KEY (replied_by)
+        REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_reviews_restaurant_id ON reviews(restaurant_id, created_at);
+CREATE INDEX idx_reviews_status ON reviews(status) WHERE status <> 'PUBLISHED';
+
+CREATE TRIGGER update_reviews_updated_at
+    BEFORE UPDATE ON reviews
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE dish_ratings (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    review_id INTEGER NOT NULL,
+    dish_id INTEGER NOT NULL,
+    rating INTEGER NOT NULL,
+    CONSTRAINT dish_ratings_review_dish_key UNIQUE (review_id, dish_id),
+    CONSTRAINT dish_ratings_rating_check CHECK (rating >= 1 AND rating <= 5),
+    CONSTRAINT fk_dish_ratings_review FOREIGN KEY (review_id)
+        REFERENCES reviews(id) ON DELETE CASCADE,
+    CONSTRAINT fk_dish_ratings_dish FOREIGN KEY (dish_id)
+        REFERENCES dishes(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_dish_ratings_dish_id ON dish_ratings(dish_id);
@@ -0,0 +1,2 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_total_amount_check,
+    ADD CONSTRAINT orders_total_amount_check CHECK (total_amount > 0) NOT VALID;
@@ -0,0 +1,3 @@
+-- Orders refunded in full or fully discounted have nothing left to pay
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_total_amount_check,
+    ADD CONSTRAINT orders_total_amount_check CHECK (total_amount >= 0);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_payments_settle_retry_at;
+
+ALTER TABLE payments
+    DROP COLUMN IF EXISTS settle_retry_at,
+    DROP COLUMN IF EXISTS settle_attempts,
+    DROP COLUMN IF EXISTS settle_error;
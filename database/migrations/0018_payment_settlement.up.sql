@@ -0,0 +1,8 @@
+-- Captures, voids and refunds the provider failed stay pending and are retried
+ALTER TABLE payments
+    ADD COLUMN settle_error TEXT,
+    ADD COLUMN settle_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN settle_retry_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_payments_settle_retry_at ON payments(settle_retry_at)
+    WHERE settle_retry_at IS NOT NULL;
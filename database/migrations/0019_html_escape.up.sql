@@ -0,0 +1,6 @@
+-- Escapes text for HTML, so search highlights only carry the <mark> tags ts_headline adds
+CREATE OR REPLACE FUNCTION html_escape(input TEXT)
+RETURNS TEXT AS $$
+    SELECT replace(replace(replace(replace(replace(input,
+        '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;');
+$$ LANGUAGE sql IMMUTABLE STRICT;
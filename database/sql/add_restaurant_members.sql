@@ -0,0 +1,26 @@
+-- Create restaurant_members table linking users to the restaurants they manage
+CREATE TABLE restaurant_members (
+    id SERIAL PRIMARY KEY,
+    restaurant_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT restaurant_members_role_check CHECK (role IN ('OWNER', 'MANAGER', 'KITCHEN_STAFF')),
+    CONSTRAINT fk_restaurant_members_restaurant FOREIGN KEY (restaurant_id)
+        REFERENCES restaurants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_restaurant_members_user FOREIGN KEY (user_id)
+        REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_restaurant_members_invited_by FOREIGN KEY (invited_by_id)
+        REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Each user can only hold one role per restaurant
+CREATE UNIQUE INDEX idx_restaurant_members_restaurant_user ON restaurant_members(restaurant_id, user_id);
+CREATE INDEX idx_restaurant_members_user_id ON restaurant_members(user_id);
+
+CREATE TRIGGER update_restaurant_members_updated_at
+    BEFORE UPDATE ON restaurant_members
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+-- Add role column to users table
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'CUSTOMER',
+    ADD CONSTRAINT users_role_check CHECK (role IN ('CUSTOMER', 'OWNER', 'ADMIN'));
+
+-- Create index for role lookups
+CREATE INDEX idx_users_role ON users(role);
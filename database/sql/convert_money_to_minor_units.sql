@@ -0,0 +1,34 @@
+-- Convert money columns from DECIMAL to integer minor units with an ISO 4217 currency.
+-- Existing amounts are assumed to be in USD.
+BEGIN;
+
+-- Restaurants declare the currency their menu is priced in
+ALTER TABLE restaurants ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Dishes: price -> price_amount, price_currency
+ALTER TABLE dishes
+    ADD COLUMN price_amount BIGINT,
+    ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE dishes SET price_amount = ROUND(price * 100);
+ALTER TABLE dishes
+    ALTER COLUMN price_amount SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS dishes_price_check,
+    DROP COLUMN price,
+    ADD CONSTRAINT dishes_price_amount_check CHECK (price_amount > 0);
+
+-- Order items: price -> price_amount, price_currency
+ALTER TABLE order_items
+    ADD COLUMN price_amount BIGINT,
+    ADD COLUMN price_currency CHAR(3) NOT NULL DEFAULT 'USD';
+UPDATE order_items SET price_amount = ROUND(price * 100);
+ALTER TABLE order_items
+    ALTER COLUMN price_amount SET NOT NULL,
+    DROP CONSTRAINT IF EXISTS order_items_price_check,
+    DROP COLUMN price,
+    ADD CONSTRAINT order_items_price_amount_check CHECK (price_amount > 0);
+
+-- Orders: total_amount becomes minor units, total_currency is added
+ALTER TABLE orders ADD COLUMN total_currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE orders ALTER COLUMN total_amount TYPE BIGINT USING ROUND(total_amount * 100);
+
+COMMIT;
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"strconv"
+	_ "time/tzdata" // restaurant time zones must resolve on hosts without a zoneinfo database
 	"tumdum_backend/api"
 	"tumdum_backend/auth"
 	"tumdum_backend/business"
 	"tumdum_backend/config"
 	"tumdum_backend/dao"
 	"tumdum_backend/database"
+	"tumdum_backend/middleware"
+
+	"gorm.io/gorm"
 )
 
 // @title Tumdum Backend API
@@ -31,23 +38,94 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Run the migrate subcommand instead of the server when requested
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if cfg.Database.AutoMigrate {
+		if err := runMigrate(db, []string{"up"}); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	}
+
 	// Initialize DAOs
+	uow := dao.NewUnitOfWork(db)
 	userDAO := dao.NewUserDAO(db)
 	restaurantDAO := dao.NewRestaurantDAO(db)
 	dishDAO := dao.NewDishDAO(db)
 	orderDAO := dao.NewOrderDAO(db)
+	memberDAO := dao.NewRestaurantMemberDAO(db)
+	searchDAO := dao.NewSearchDAO(db)
+	addressDAO := dao.NewUserAddressDAO(db)
+	scheduleDAO := dao.NewScheduleDAO(db)
+	cartDAO := dao.NewCartDAO(db)
+	modifierDAO := dao.NewModifierDAO(db)
+	menuDAO := dao.NewMenuDAO(db)
+	paymentDAO := dao.NewPaymentDAO(db)
+	refundDAO := dao.NewRefundDAO(db)
+	promotionDAO := dao.NewPromotionDAO(db)
+	driverDAO := dao.NewDriverDAO(db)
+	offerDAO := dao.NewDeliveryOfferDAO(db)
+	reviewDAO := dao.NewReviewDAO(db)
+
+	// Authenticated requests use the user's current role rather than the token's
+	middleware.Initialize(userDAO)
+
+	// Initialize the payment provider
+	var paymentProvider business.PaymentProvider
+	switch cfg.Payments.Provider {
+	case "", "mock":
+		// An empty key still yields valid signatures, which anyone could forge
+		if cfg.Payments.WebhookSecret == "" {
+			log.Fatal("payments.webhook_secret must be set")
+		}
+		paymentProvider = business.NewMockPaymentProvider(cfg.Payments.WebhookSecret)
+	default:
+		log.Fatalf("Unknown payment provider %q", cfg.Payments.Provider)
+	}
 
 	// Initialize services
 	userService := business.NewUserService(userDAO)
-	restaurantService := business.NewRestaurantService(restaurantDAO)
-	dishService := business.NewDishService(dishDAO)
-	orderService := business.NewOrderService(orderDAO, dishDAO, restaurantDAO)
+	restaurantService := business.NewRestaurantService(uow, restaurantDAO, memberDAO, userDAO, scheduleDAO)
+	dishService := business.NewDishService(dishDAO, restaurantDAO, memberDAO, modifierDAO, menuDAO)
+	pricer := business.NewOrderPricer(cfg.Pricing)
+	estimator := business.NewOrderEstimator(cfg.Estimates)
+	orderEvents := business.NewOrderEventHub()
+	paymentService := business.NewPaymentService(uow, paymentDAO, orderDAO, memberDAO, paymentProvider)
+	orderService := business.NewOrderService(uow, orderDAO, memberDAO, refundDAO, cfg.Scheduling, paymentService, pricer, estimator, orderEvents)
+	refundService := business.NewRefundService(uow, refundDAO, orderDAO, memberDAO, paymentService, pricer, orderEvents)
+	promotionService := business.NewPromotionService(promotionDAO, restaurantDAO)
+	deliveryService := business.NewDeliveryService(uow, driverDAO, offerDAO, orderDAO, memberDAO, orderEvents, cfg.Delivery)
+	reviewService := business.NewReviewService(uow, reviewDAO, orderDAO, memberDAO)
+	searchService := business.NewSearchService(searchDAO)
+	addressService := business.NewAddressService(addressDAO)
+	cartService := business.NewCartService(uow, cartDAO, dishDAO, orderService)
+	menuService := business.NewMenuService(menuDAO, restaurantDAO, memberDAO)
+
+	// Release scheduled orders to their restaurants in the background
+	orderScheduler := business.NewOrderScheduler(orderService)
+	orderScheduler.Start()
+	defer orderScheduler.Stop()
+
+	// Refill the daily stock of dishes at the start of each restaurant's day
+	stockResetJob := business.NewStockResetJob(dishService)
+	stockResetJob.Start()
+	defer stockResetJob.Stop()
+
+	// Offer ready orders to nearby drivers and expire unanswered offers
+	deliveryDispatcher := business.NewDeliveryDispatcher(deliveryService)
+	deliveryDispatcher.Start()
+	defer deliveryDispatcher.Stop()
 
 	// Initialize image handler
 	imageHandler := api.NewImageHandler("uploads")
 
 	// Initialize server
-	server := api.NewServer(restaurantService, dishService, orderService, userService, cfg, imageHandler)
+	server := api.NewServer(restaurantService, dishService, orderService, userService, searchService, addressService, cartService, menuService, paymentService, refundService, promotionService, deliveryService, reviewService, cfg, imageHandler)
 
 	// Start server
 	log.Printf("Server starting on port %d...", cfg.Server.Port)
@@ -55,3 +133,69 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// runMigrate handles "migrate up", "migrate down [steps]", "migrate status"
+// and "migrate baseline <version>"
+func runMigrate(db *gorm.DB, args []string) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		count, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", count)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		count, err := migrator.Down(steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Reverted %d migration(s)", count)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if status.ChecksumMismatch {
+				state += " (checksum mismatch)"
+			}
+			fmt.Printf("%04d  %-40s %s\n", status.Version, status.Name, state)
+		}
+	case "baseline":
+		if len(args) < 2 {
+			return fmt.Errorf("baseline requires a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		count, err := migrator.Baseline(version)
+		if err != nil {
+			return err
+		}
+		log.Printf("Marked %d migration(s) as applied", count)
+	default:
+		return fmt.Errorf("unknown migrate command %q (use up, down, status or baseline)", command)
+	}
+	return nil
+}
@@ -25,15 +25,43 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := auth.ValidateToken(parts[1])
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+		authenticate(c, parts[1])
+	}
+}
+
+// StreamAuthMiddleware authenticates like AuthMiddleware but also accepts the
+// token in the "token" query parameter, since browsers cannot set headers on
+// EventSource and WebSocket connections
+func StreamAuthMiddleware() gin.HandlerFunc {
+	headerAuth := AuthMiddleware()
+	return func(c *gin.Context) {
+		if token := c.Query("token"); token != "" && c.GetHeader("Authorization") == "" {
+			authenticate(c, token)
 			return
 		}
+		headerAuth(c)
+	}
+}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Next()
+// authenticate validates the token and stores its claims on the context
+func authenticate(c *gin.Context, token string) {
+	claims, err := auth.ValidateToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		c.Abort()
+		return
 	}
+	// The role is looked up again so that demoted users lose their rights at once;
+	// tokens of deleted users stop working
+	role, err := currentRole(claims)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("email", claims.Email)
+	c.Set("role", role)
+	c.Next()
 }
@@ -0,0 +1,22 @@
+package middleware
+
+import "testing"
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/orders/1/events", "/api/orders/1/events"},
+		{"/api/orders/1/events?token=eyJhbGciOiJIUzI1NiJ9.e30.sig", "/api/orders/1/events?token=REDACTED"},
+		{"/api/restaurants/2/ws?since=5&token=abc&x=1", "/api/restaurants/2/ws?since=5&token=REDACTED&x=1"},
+		{"/api/orders?token", "/api/orders?token=REDACTED"},
+		{"/api/search?q=token&tokens=1", "/api/search?q=token&tokens=1"},
+		{"/api/orders/1/events?", "/api/orders/1/events?"},
+	}
+	for _, tt := range tests {
+		if got := redactQuery(tt.path); got != tt.want {
+			t.Errorf("redactQuery(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"tumdum_backend/auth"
+	"tumdum_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleSource looks up the current role of a user
+type RoleSource interface {
+	GetRole(userID uint) (models.UserRole, error)
+}
+
+var roleSource RoleSource
+
+// Initialize sets where authenticated requests look up the user's role, so that a
+// role change applies at once instead of when the user's token expires
+func Initialize(source RoleSource) {
+	roleSource = source
+}
+
+// RequireRoles is a middleware that only lets through users holding one of the given roles.
+// It must be registered after AuthMiddleware.
+func RequireRoles(roles ...models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := GetUserRole(c)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// GetUserID returns the authenticated user's ID from the context
+func GetUserID(c *gin.Context) uint {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uint)
+	return id
+}
+
+// GetUserRole returns the authenticated user's role from the context
+func GetUserRole(c *gin.Context) models.UserRole {
+	role, _ := c.Get("role")
+	userRole, _ := role.(models.UserRole)
+	return userRole
+}
+
+// currentRole returns the user's role as it is now, or the role carried in the
+// token when no role source is set. Users without a role, such as those holding
+// tokens issued before roles existed, are customers.
+func currentRole(claims *auth.Claims) (models.UserRole, error) {
+	role := claims.Role
+	if roleSource != nil {
+		var err error
+		if role, err = roleSource.GetRole(claims.UserID); err != nil {
+			return "", err
+		}
+	}
+	if role == "" {
+		return models.UserRoleCustomer, nil
+	}
+	return role, nil
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"tumdum_backend/auth"
+	"tumdum_backend/models"
+)
+
+type roleSourceFunc func(userID uint) (models.UserRole, error)
+
+func (f roleSourceFunc) GetRole(userID uint) (models.UserRole, error) {
+	return f(userID)
+}
+
+func TestCurrentRole(t *testing.T) {
+	roles := map[uint]models.UserRole{1: models.UserRoleCustomer, 2: models.UserRoleAdmin, 3: ""}
+	source := roleSourceFunc(func(userID uint) (models.UserRole, error) {
+		role, ok := roles[userID]
+		if !ok {
+			return "", errors.New("user not found")
+		}
+		return role, nil
+	})
+
+	tests := []struct {
+		name    string
+		source  RoleSource
+		claims  auth.Claims
+		want    models.UserRole
+		wantErr bool
+	}{
+		{name: "demoted admin", source: source, claims: auth.Claims{UserID: 1, Role: models.UserRoleAdmin}, want: models.UserRoleCustomer},
+		{name: "promoted customer", source: source, claims: auth.Claims{UserID: 2, Role: models.UserRoleCustomer}, want: models.UserRoleAdmin},
+		{name: "user without a role", source: source, claims: auth.Claims{UserID: 3}, want: models.UserRoleCustomer},
+		{name: "deleted user", source: source, claims: auth.Claims{UserID: 4, Role: models.UserRoleDriver}, wantErr: true},
+		{name: "no role source", claims: auth.Claims{UserID: 4, Role: models.UserRoleDriver}, want: models.UserRoleDriver},
+		{name: "token without a role", claims: auth.Claims{UserID: 4}, want: models.UserRoleCustomer},
+	}
+	defer Initialize(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Initialize(tt.source)
+			got, err := currentRole(&tt.claims)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("currentRole() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
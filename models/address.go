@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// UserAddress is a saved delivery address of a user
+type UserAddress struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	Label      string    `json:"label"`
+	Address    string    `json:"address" gorm:"not null"`
+	City       string    `json:"city"`
+	State      string    `json:"state"`
+	Country    string    `json:"country"`
+	PostalCode string    `json:"postal_code"`
+	Latitude   float64   `json:"latitude" gorm:"type:double precision;not null"`
+	Longitude  float64   `json:"longitude" gorm:"type:double precision;not null"`
+	IsDefault  bool      `json:"is_default" gorm:"not null;default:false"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+)
+
+// Cart holds the dishes a user intends to order. A cart only ever contains dishes
+// of a single restaurant.
+type Cart struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	RestaurantID *uint      `json:"restaurant_id"`
+	Items        []CartItem `json:"items" gorm:"foreignKey:CartID"`
+	// Subtotal is computed from the current prices of the available items
+	Subtotal *Money `json:"subtotal,omitempty" gorm:"-"`
+}
+
+// CartItem is a dish with its selected modifiers and a quantity in a cart. Prices
+// are not stored but read live from the dish and options whenever the cart is returned.
+type CartItem struct {
+	ID        uint               `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	CartID    uint               `json:"cart_id" gorm:"not null;index"`
+	DishID    uint               `json:"dish_id" gorm:"not null"`
+	Dish      Dish               `json:"dish" gorm:"foreignKey:DishID"`
+	Quantity  int                `json:"quantity" gorm:"not null"`
+	Modifiers []CartItemModifier `json:"modifiers" gorm:"foreignKey:CartItemID"`
+	// UnitPrice, LineTotal and IsAvailable are computed when the cart is returned
+	UnitPrice   Money `json:"unit_price" gorm:"-"`
+	LineTotal   Money `json:"line_total" gorm:"-"`
+	IsAvailable bool  `json:"is_available" gorm:"-"`
+}
+
+// CartItemRequest adds a dish with the selected modifier options to the cart
+type CartItemRequest struct {
+	DishID            uint   `json:"dish_id" binding:"required"`
+	Quantity          int    `json:"quantity" binding:"required,min=1"`
+	ModifierOptionIDs []uint `json:"modifier_option_ids"`
+}
+
+// CartItemUpdate changes the quantity of a cart item. A quantity of zero removes it.
+type CartItemUpdate struct {
+	Quantity int `json:"quantity" binding:"min=0"`
+}
+
+// CartCheckout holds the order details that are not part of the cart
+type CartCheckout struct {
+	DeliveryAddressID *uint      `json:"delivery_address_id"`
+	DeliveryLatitude  *float64   `json:"delivery_latitude"`
+	DeliveryLongitude *float64   `json:"delivery_longitude"`
+	ScheduledFor      *time.Time `json:"scheduled_for"`
+	PromoCode         string     `json:"promo_code"`
+	TipAmount         Money      `json:"tip_amount"`
+}
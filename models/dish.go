@@ -5,15 +5,33 @@ import (
 )
 
 type Dish struct {
-	ID           uint       `gorm:"primarykey" json:"id"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	Name         string     `json:"name"`
-	Description  string     `json:"description"`
-	Price        float64    `json:"price"`
-	RestaurantID uint       `json:"restaurant_id"`
-	Restaurant   Restaurant `json:"restaurant" gorm:"foreignKey:RestaurantID"`
-	Category     string     `json:"category"`
-	IsAvailable  bool       `json:"is_available" gorm:"default:true"`
-	ImageURL     string     `json:"image_url"`
+	ID             uint       `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Price          Money      `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	RestaurantID   uint       `json:"restaurant_id"`
+	Restaurant     Restaurant `json:"restaurant" gorm:"foreignKey:RestaurantID"`
+	Category       string     `json:"category"`
+	MenuCategoryID *uint      `json:"menu_category_id"`
+	DisplayOrder   int        `json:"display_order" gorm:"not null;default:0"` // position within the menu category
+	IsAvailable    bool       `json:"is_available" gorm:"default:true"`
+	ImageURL       string     `json:"image_url"`
+	DailyStock     *int       `json:"daily_stock"`     // portions per day, nil when stock is not tracked
+	StockRemaining *int       `json:"stock_remaining"` // portions left today
+	StockResetAt   *time.Time `json:"stock_reset_at"`
+	Rating         float32    `json:"rating" gorm:"type:decimal(3,2);default:0.0"` // average of the dish ratings in reviews that are not hidden
+	RatingCount    int        `json:"rating_count" gorm:"not null;default:0"`
+	// PrepTimeMinutes is how long the kitchen takes to prepare the dish, 0 for the default
+	PrepTimeMinutes int `json:"prep_time_minutes" gorm:"not null;default:0"`
+	// ModifierGroups are the options offered with the dish, e.g. sizes or toppings
+	ModifierGroups []ModifierGroup `json:"modifier_groups,omitempty" gorm:"foreignKey:DishID"`
+}
+
+// DishStockUpdate sets the stock of a dish. A nil DailyStock stops tracking stock;
+// a nil StockRemaining starts the day over with the full daily stock.
+type DishStockUpdate struct {
+	DailyStock     *int `json:"daily_stock" binding:"omitempty,min=0"`
+	StockRemaining *int `json:"stock_remaining" binding:"omitempty,min=0"`
 }
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+)
+
+// Driver is the delivery profile of a user with the DRIVER role. Available drivers
+// with a recent location are offered ready orders near them.
+type Driver struct {
+	ID                uint       `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	UserID            uint       `json:"user_id" gorm:"not null;uniqueIndex"`
+	User              User       `json:"user" gorm:"foreignKey:UserID"`
+	VehicleType       string     `json:"vehicle_type"`
+	LicensePlate      string     `json:"license_plate"`
+	IsAvailable       bool       `json:"is_available" gorm:"not null;default:false"`
+	Latitude          *float64   `json:"latitude" gorm:"type:double precision"`
+	Longitude         *float64   `json:"longitude" gorm:"type:double precision"`
+	LocationUpdatedAt *time.Time `json:"location_updated_at"`
+
+	// DistanceKm is only populated by distance-based queries
+	DistanceKm *float64 `json:"distance_km,omitempty" gorm:"->;-:migration"`
+}
+
+// ActiveDeliveryStatuses are the statuses of orders a driver is delivering
+var ActiveDeliveryStatuses = []OrderStatus{OrderStatusReady, OrderStatusPickedUp, OrderStatusOutForDelivery}
+
+// DriverAvailability starts or ends a driver's shift
+type DriverAvailability struct {
+	IsAvailable *bool `json:"is_available" binding:"required"`
+}
+
+// DriverLocationUpdate is a location ping sent by a driver's device
+type DriverLocationUpdate struct {
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+}
+
+// DriverLocation is a location ping of a driver recorded while delivering an order
+type DriverLocation struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	DriverID  uint      `json:"driver_id" gorm:"not null"`
+	OrderID   uint      `json:"order_id" gorm:"not null;index"`
+	Latitude  float64   `json:"latitude" gorm:"type:double precision;not null"`
+	Longitude float64   `json:"longitude" gorm:"type:double precision;not null"`
+}
+
+type DeliveryOfferStatus string
+
+const (
+	DeliveryOfferStatusOffered  DeliveryOfferStatus = "OFFERED"
+	DeliveryOfferStatusAccepted DeliveryOfferStatus = "ACCEPTED"
+	DeliveryOfferStatusDeclined DeliveryOfferStatus = "DECLINED"
+	DeliveryOfferStatusExpired  DeliveryOfferStatus = "EXPIRED"
+)
+
+// DeliveryOffer offers a ready order to one driver at a time. An offer that is
+// declined or not accepted before it expires passes the order on to another driver.
+type DeliveryOffer struct {
+	ID          uint                `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	OrderID     uint                `json:"order_id" gorm:"not null;index"`
+	Order       *Order              `json:"order,omitempty" gorm:"foreignKey:OrderID"`
+	DriverID    uint                `json:"driver_id" gorm:"not null;index"` // the driver's user ID
+	Status      DeliveryOfferStatus `json:"status" gorm:"type:varchar(20);not null"`
+	DistanceKm  float64             `json:"distance_km" gorm:"type:double precision"` // from the driver to the restaurant
+	ExpiresAt   time.Time           `json:"expires_at"`
+	RespondedAt *time.Time          `json:"responded_at"`
+}
+
+// DeliveryTracking is the delivery view of an order: its driver and the route
+// they have taken so far, oldest ping first
+type DeliveryTracking struct {
+	OrderID           uint             `json:"order_id"`
+	Status            OrderStatus      `json:"status"`
+	DriverID          *uint            `json:"driver_id"`
+	DriverName        string           `json:"driver_name,omitempty"`
+	DriverPhone       string           `json:"driver_phone,omitempty"`
+	VehicleType       string           `json:"vehicle_type,omitempty"`
+	LicensePlate      string           `json:"license_plate,omitempty"`
+	DeliveryLatitude  *float64         `json:"delivery_latitude"`
+	DeliveryLongitude *float64         `json:"delivery_longitude"`
+	Location          *DriverLocation  `json:"location"` // latest ping, nil until the driver sends one
+	Route             []DriverLocation `json:"route"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"math"
+)
+
+// EarthRadiusKm is the mean radius of the earth used for distance calculations
+const EarthRadiusKm = 6371.0
+
+// DefaultDeliveryRadiusKm is the delivery radius of restaurants that do not set one
+const DefaultDeliveryRadiusKm = 5.0
+
+// ValidCoordinates reports whether lat and lng are a valid position in decimal degrees
+func ValidCoordinates(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180
+}
+
+// DistanceKm returns the great-circle (haversine) distance between two points
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLng := (lng2 - lng1) * math.Pi / 180
+	a := math.Pow(math.Sin(dLat/2), 2) +
+		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(a))
+}
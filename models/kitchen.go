@@ -0,0 +1,32 @@
+package models
+
+// KitchenStatuses are the statuses of orders on a restaurant's kitchen display
+var KitchenStatuses = []OrderStatus{OrderStatusConfirmed, OrderStatusPreparing, OrderStatusReady}
+
+// KitchenOrder is an order on the kitchen display with how long it has been in
+// its current status
+type KitchenOrder struct {
+	Order
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+}
+
+// KitchenQueue is the kitchen display of a restaurant: its active orders grouped
+// by status, longest waiting first
+type KitchenQueue struct {
+	Confirmed []KitchenOrder `json:"confirmed"`
+	Preparing []KitchenOrder `json:"preparing"`
+	Ready     []KitchenOrder `json:"ready"`
+}
+
+// KitchenAdvanceRequest moves a batch of orders to their next kitchen status
+type KitchenAdvanceRequest struct {
+	OrderIDs []uint `json:"order_ids" binding:"required,min=1,max=100"`
+}
+
+// KitchenAdvanceResult is the outcome for one order of a bulk advance. Status is
+// the order's new status, Error why it was not moved.
+type KitchenAdvanceResult struct {
+	OrderID uint        `json:"order_id"`
+	Status  OrderStatus `json:"status,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// MenuCategory is a section of a restaurant's menu such as "Starters" or
+// "Breakfast". A category with AvailableFrom and AvailableUntil is only offered
+// between those times of day in the restaurant's time zone; a window that ends at
+// or before its start runs past midnight.
+type MenuCategory struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	RestaurantID   uint      `json:"restaurant_id" gorm:"not null;index"`
+	Name           string    `json:"name" gorm:"not null"`
+	Description    string    `json:"description"`
+	DisplayOrder   int       `json:"display_order" gorm:"not null;default:0"`
+	AvailableFrom  *string   `json:"available_from" gorm:"type:varchar(8)"`
+	AvailableUntil *string   `json:"available_until" gorm:"type:varchar(8)"`
+	IsActive       bool      `json:"is_active" gorm:"default:true"`
+	Dishes         []Dish    `json:"dishes,omitempty" gorm:"foreignKey:MenuCategoryID"`
+	// IsAvailableNow is computed from the availability window when the category is returned
+	IsAvailableNow bool `json:"is_available_now" gorm:"-"`
+}
+
+// Menu is the currently available menu of a restaurant, grouped by category in
+// display order. Available dishes without a category are listed under OtherDishes.
+type Menu struct {
+	RestaurantID uint           `json:"restaurant_id"`
+	TimeZone     string         `json:"time_zone"`
+	Categories   []MenuCategory `json:"categories"`
+	OtherDishes  []Dish         `json:"other_dishes"`
+}
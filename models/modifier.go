@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// ModifierGroup is a set of options a customer picks from when ordering a dish,
+// such as a size or extra toppings
+type ModifierGroup struct {
+	ID            uint             `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	DishID        uint             `json:"dish_id" gorm:"not null;index"`
+	Name          string           `json:"name" gorm:"not null"`
+	IsRequired    bool             `json:"is_required"`
+	MinSelections int              `json:"min_selections" gorm:"not null;default:0"`
+	MaxSelections int              `json:"max_selections" gorm:"not null;default:0"` // 0 is unlimited
+	DisplayOrder  int              `json:"display_order" gorm:"not null;default:0"`
+	Options       []ModifierOption `json:"options" gorm:"foreignKey:ModifierGroupID"`
+}
+
+// ModifierOption is a single choice of a modifier group. Its price is added to the
+// dish price and may be zero.
+type ModifierOption struct {
+	ID              uint      `gorm:"primarykey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	ModifierGroupID uint      `json:"modifier_group_id" gorm:"not null;index"`
+	Name            string    `json:"name" gorm:"not null"`
+	Price           Money     `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	IsAvailable     bool      `json:"is_available" gorm:"default:true"`
+	DisplayOrder    int       `json:"display_order" gorm:"not null;default:0"`
+}
+
+// MinRequired returns how many options must be selected from the group
+func (g *ModifierGroup) MinRequired() int {
+	if g.IsRequired && g.MinSelections < 1 {
+		return 1
+	}
+	return g.MinSelections
+}
+
+// OrderItemModifier is a modifier option selected for an order item. The group
+// name, option name and price are copied from the option when the order is priced
+// so later menu changes do not alter past orders.
+type OrderItemModifier struct {
+	ID               uint      `gorm:"primarykey" json:"id"`
+	CreatedAt        time.Time `json:"created_at"`
+	OrderItemID      uint      `json:"order_item_id" gorm:"not null;index"`
+	ModifierOptionID *uint     `json:"modifier_option_id"`
+	GroupName        string    `json:"group_name"`
+	Name             string    `json:"name"`
+	Price            Money     `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+}
+
+// CartItemModifier is a modifier option selected for a cart item
+type CartItemModifier struct {
+	ID               uint           `gorm:"primarykey" json:"id"`
+	CartItemID       uint           `json:"cart_item_id" gorm:"not null;index"`
+	ModifierOptionID uint           `json:"modifier_option_id" gorm:"not null"`
+	ModifierOption   ModifierOption `json:"modifier_option" gorm:"foreignKey:ModifierOptionID"`
+}
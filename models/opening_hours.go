@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// clockLayout is the format of the opening and closing times of a restaurant
+const clockLayout = "15:04:05"
+
+// OpeningHours is one interval during which a restaurant is open on a day of the
+// week. A day may have several intervals. An interval that closes at or before
+// its opening time runs past midnight into the next day.
+type OpeningHours struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	DayOfWeek    int       `json:"day_of_week" gorm:"not null"` // 0 is Sunday, as in time.Weekday
+	OpensAt      string    `json:"opens_at" gorm:"type:varchar(8);not null"`
+	ClosesAt     string    `json:"closes_at" gorm:"type:varchar(8);not null"`
+}
+
+// RestaurantClosure is a holiday or other exception during which the restaurant
+// is closed regardless of its opening hours
+type RestaurantClosure struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	RestaurantID uint      `json:"restaurant_id" gorm:"not null;index"`
+	StartsAt     time.Time `json:"starts_at" gorm:"not null"`
+	EndsAt       time.Time `json:"ends_at" gorm:"not null"`
+	Reason       string    `json:"reason"`
+}
+
+// RestaurantSchedule is the weekly opening hours and upcoming closures of a restaurant
+type RestaurantSchedule struct {
+	RestaurantID uint                `json:"restaurant_id"`
+	TimeZone     string              `json:"time_zone"`
+	IsOpenNow    bool                `json:"is_open_now"`
+	OpeningHours []OpeningHours      `json:"opening_hours"`
+	Closures     []RestaurantClosure `json:"closures"`
+}
+
+// ParseClock parses a time of day given as HH:MM or HH:MM:SS and returns the
+// time elapsed since midnight
+func ParseClock(value string) (time.Duration, error) {
+	if len(value) == len("15:04") {
+		value += ":00"
+	}
+	parsed, err := time.Parse(clockLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour +
+		time.Duration(parsed.Minute())*time.Minute +
+		time.Duration(parsed.Second())*time.Second, nil
+}
+
+// FormatClock formats a time elapsed since midnight as HH:MM:SS
+func FormatClock(d time.Duration) string {
+	return time.Time{}.Add(d).Format(clockLayout)
+}
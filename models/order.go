@@ -7,35 +7,108 @@ import (
 type OrderStatus string
 
 const (
-	OrderStatusPending   OrderStatus = "PENDING"
-	OrderStatusConfirmed OrderStatus = "CONFIRMED"
-	OrderStatusPreparing OrderStatus = "PREPARING"
-	OrderStatusReady     OrderStatus = "READY"
-	OrderStatusDelivered OrderStatus = "DELIVERED"
-	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusScheduled      OrderStatus = "SCHEDULED"
+	OrderStatusPending        OrderStatus = "PENDING"
+	OrderStatusConfirmed      OrderStatus = "CONFIRMED"
+	OrderStatusPreparing      OrderStatus = "PREPARING"
+	OrderStatusReady          OrderStatus = "READY"
+	OrderStatusPickedUp       OrderStatus = "PICKED_UP"
+	OrderStatusOutForDelivery OrderStatus = "OUT_FOR_DELIVERY"
+	OrderStatusDelivered      OrderStatus = "DELIVERED"
+	OrderStatusCancelled      OrderStatus = "CANCELLED"
 )
 
+// IsFinal reports whether the order can no longer change status
+func (s OrderStatus) IsFinal() bool {
+	return s == OrderStatusDelivered || s == OrderStatusCancelled
+}
+
 type Order struct {
-	ID           uint        `gorm:"primarykey" json:"id"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
-	UserID       uint        `json:"user_id"`
-	User         User        `json:"user" gorm:"foreignKey:UserID"`
-	RestaurantID uint        `json:"restaurant_id"`
-	Restaurant   Restaurant  `json:"restaurant" gorm:"foreignKey:RestaurantID"`
-	Status       OrderStatus `json:"status" gorm:"type:varchar(20);default:'PENDING'"`
-	TotalAmount  float64     `json:"total_amount"`
-	OrderItems   []OrderItem `json:"order_items" gorm:"foreignKey:OrderID"`
+	ID                 uint            `gorm:"primarykey" json:"id"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+	UserID             uint            `json:"user_id"`
+	User               User            `json:"user" gorm:"foreignKey:UserID"`
+	RestaurantID       uint            `json:"restaurant_id"`
+	Restaurant         Restaurant      `json:"restaurant" gorm:"foreignKey:RestaurantID"`
+	Status             OrderStatus     `json:"status" gorm:"type:varchar(20);default:'PENDING'"`
+	SubtotalAmount     Money           `json:"subtotal_amount" gorm:"embedded;embeddedPrefix:subtotal_"`
+	DiscountAmount     Money           `json:"discount_amount" gorm:"embedded;embeddedPrefix:discount_"`
+	TaxRate            float64         `json:"tax_rate" gorm:"type:numeric(6,3);not null;default:0"` // percent of the discounted subtotal
+	TaxAmount          Money           `json:"tax_amount" gorm:"embedded;embeddedPrefix:tax_"`
+	DeliveryFee        Money           `json:"delivery_fee" gorm:"embedded;embeddedPrefix:delivery_fee_"`
+	PackagingFee       Money           `json:"packaging_fee" gorm:"embedded;embeddedPrefix:packaging_fee_"`
+	TipAmount          Money           `json:"tip_amount" gorm:"embedded;embeddedPrefix:tip_"`
+	TotalAmount        Money           `json:"total_amount" gorm:"embedded;embeddedPrefix:total_"`
+	DeliveryAddressID  *uint           `json:"delivery_address_id"`
+	DeliveryAddress    string          `json:"delivery_address"`
+	DeliveryLatitude   *float64        `json:"delivery_latitude" gorm:"type:double precision"`
+	DeliveryLongitude  *float64        `json:"delivery_longitude" gorm:"type:double precision"`
+	DeliveryDistanceKm *float64        `json:"delivery_distance_km" gorm:"type:double precision"`
+	ScheduledFor       *time.Time      `json:"scheduled_for"`
+	DriverID           *uint           `json:"driver_id"`
+	StockReservedAt    *time.Time      `json:"-"` // when the items were taken from the dishes' daily stock
+	OrderItems         []OrderItem     `json:"order_items" gorm:"foreignKey:OrderID"`
+	Discounts          []OrderDiscount `json:"discounts" gorm:"foreignKey:OrderID"`
+	Payments           []Payment       `json:"payments,omitempty" gorm:"foreignKey:OrderID"`
+	PromoCode          string          `json:"promo_code,omitempty" gorm:"-"` // applied when the order is placed
+
+	// Estimates are set when the order is confirmed and revised as it moves along
+	EstimatedReadyAt    *time.Time `json:"estimated_ready_at"`
+	EstimatedDeliveryAt *time.Time `json:"estimated_delivery_at"`
+
+	// StatusChangedAt is only populated by the kitchen queue query
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" gorm:"->;-:migration"`
 }
 
+// OrderItem is a dish ordered with its selected modifiers. Price is the dish price
+// and LineTotal adds the modifier prices and multiplies by the quantity. Refunded
+// items stay on the order but no longer count towards its total.
 type OrderItem struct {
-	ID        uint      `gorm:"primarykey" json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	OrderID   uint      `json:"order_id"`
-	Order     Order     `json:"-" gorm:"foreignKey:OrderID"`
-	DishID    uint      `json:"dish_id"`
-	Dish      Dish      `json:"dish" gorm:"foreignKey:DishID"`
-	Quantity  int       `json:"quantity"`
-	Price     float64   `json:"price"`
+	ID               uint                `gorm:"primarykey" json:"id"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+	OrderID          uint                `json:"order_id"`
+	Order            Order               `json:"-" gorm:"foreignKey:OrderID"`
+	DishID           uint                `json:"dish_id"`
+	Dish             Dish                `json:"dish" gorm:"foreignKey:DishID"`
+	Quantity         int                 `json:"quantity"`
+	RefundedQuantity int                 `json:"refunded_quantity" gorm:"not null;default:0"`
+	Price            Money               `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	LineTotal        Money               `json:"line_total" gorm:"embedded;embeddedPrefix:line_total_"`
+	Modifiers        []OrderItemModifier `json:"modifiers" gorm:"foreignKey:OrderItemID"`
+}
+
+// RemainingQuantity is the quantity that has not been refunded
+func (i *OrderItem) RemainingQuantity() int {
+	return i.Quantity - i.RefundedQuantity
+}
+
+// RemainingTotal is the part of the line total that has not been refunded
+func (i *OrderItem) RemainingTotal() Money {
+	if i.Quantity == 0 {
+		return NewMoney(0, i.LineTotal.Currency)
+	}
+	return NewMoney(i.LineTotal.Amount*int64(i.RemainingQuantity())/int64(i.Quantity), i.LineTotal.Currency)
+}
+
+// OrderStatusEvent records a single status transition of an order. Refund
+// requests and reviews are recorded too, with the refund they concern.
+type OrderStatusEvent struct {
+	ID         uint        `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	OrderID    uint        `json:"order_id" gorm:"not null;index"`
+	FromStatus OrderStatus `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus   OrderStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+	ActorID    *uint       `json:"actor_id"`
+	ActorRole  UserRole    `json:"actor_role,omitempty" gorm:"->;-:migration"` // role of the actor's account
+	Reason     string      `json:"reason"`
+	RefundID   *uint       `json:"refund_id,omitempty"`
+}
+
+// DeliverySlot is a bookable period for a scheduled order
+type DeliverySlot struct {
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Remaining *int      `json:"remaining,omitempty"` // nil when the restaurant has no slot capacity limit
 }
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+type OrderEventType string
+
+const (
+	OrderEventCreated        OrderEventType = "order.created"
+	OrderEventStatusChanged  OrderEventType = "order.status_changed"
+	OrderEventDriverAssigned OrderEventType = "order.driver_assigned"
+)
+
+// OrderEvent is a change to an order pushed to live subscribers. New orders
+// carry the order itself so dashboards can list them without fetching it.
+type OrderEvent struct {
+	Type         OrderEventType `json:"type"`
+	OrderID      uint           `json:"order_id"`
+	RestaurantID uint           `json:"restaurant_id"`
+	UserID       uint           `json:"user_id"`
+	DriverID     *uint          `json:"driver_id,omitempty"`
+	FromStatus   OrderStatus    `json:"from_status,omitempty"`
+	Status       OrderStatus    `json:"status"`
+	Reason       string         `json:"reason,omitempty"`
+	Order        *Order         `json:"order,omitempty"`
+	OccurredAt   time.Time      `json:"occurred_at"`
+}
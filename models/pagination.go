@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageRequest describes a cursor-paginated, sorted list query
+type PageRequest struct {
+	// Cursor is the opaque next_cursor returned with the previous page
+	Cursor string
+	Limit  int
+	// Sort is a field name, prefixed with "-" for descending order
+	Sort string
+}
+
+// Page is a single page of list results
+type Page[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+// RestaurantFilter narrows a restaurant list query
+type RestaurantFilter struct {
+	Cuisine   string
+	City      string
+	IsActive  *bool
+	MinRating *float64
+}
+
+// DishFilter narrows a dish list query
+type DishFilter struct {
+	Category       string
+	MenuCategoryID uint
+	IsAvailable    *bool
+}
+
+// OrderFilter narrows an order list query
+type OrderFilter struct {
+	UserID       uint
+	RestaurantID uint
+	Statuses     []OrderStatus
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	// Upcoming limits the list to active orders scheduled for a future slot
+	Upcoming bool
+}
+
+// PromotionFilter narrows a promotion list query
+type PromotionFilter struct {
+	Code         string
+	RestaurantID uint
+	IsActive     *bool
+}
+
+// ReviewFilter narrows a review list query
+type ReviewFilter struct {
+	RestaurantID uint
+	Statuses     []ReviewStatus
+}
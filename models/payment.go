@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusCaptured   PaymentStatus = "CAPTURED"
+	PaymentStatusFailed     PaymentStatus = "FAILED"
+	PaymentStatusVoided     PaymentStatus = "VOIDED"
+	PaymentStatusRefunded   PaymentStatus = "REFUNDED"
+)
+
+// Payment is an attempt to pay for an order through a payment provider. The
+// amount is authorized when the order is paid for, captured once it is delivered
+// and voided or refunded when it is cancelled.
+type Payment struct {
+	ID                uint          `gorm:"primarykey" json:"id"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	OrderID           uint          `json:"order_id" gorm:"not null;index"`
+	Provider          string        `json:"provider" gorm:"type:varchar(50);not null"`
+	ProviderReference string        `json:"provider_reference" gorm:"type:varchar(255)"`
+	Status            PaymentStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Amount            Money         `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	CapturedAmount    Money         `json:"captured_amount" gorm:"embedded;embeddedPrefix:captured_"`
+	RefundedAmount    Money         `json:"refunded_amount" gorm:"embedded;embeddedPrefix:refunded_"`
+	FailureReason     string        `json:"failure_reason,omitempty"`
+
+	// A capture, void or refund the provider failed keeps the payment in its
+	// status and is tried again at SettleRetryAt
+	SettleError    string     `json:"settle_error,omitempty"`
+	SettleAttempts int        `json:"settle_attempts,omitempty"`
+	SettleRetryAt  *time.Time `json:"settle_retry_at,omitempty"`
+}
+
+// PaymentRequest pays for an order with a payment method token issued by the
+// provider's client SDK
+type PaymentRequest struct {
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type DiscountType string
+
+const (
+	DiscountTypePercentage  DiscountType = "PERCENTAGE"
+	DiscountTypeFixedAmount DiscountType = "FIXED_AMOUNT"
+)
+
+// Promotion is a promo code giving a discount on orders. Without a restaurant it
+// applies to orders of every restaurant.
+type Promotion struct {
+	ID                    uint         `gorm:"primarykey" json:"id"`
+	CreatedAt             time.Time    `json:"created_at"`
+	UpdatedAt             time.Time    `json:"updated_at"`
+	Code                  string       `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Description           string       `json:"description"`
+	DiscountType          DiscountType `json:"discount_type" gorm:"type:varchar(20);not null"`
+	PercentOff            int          `json:"percent_off"`
+	AmountOff             Money        `json:"amount_off" gorm:"embedded;embeddedPrefix:amount_off_"`
+	RestaurantID          *uint        `json:"restaurant_id" gorm:"index"`
+	MinOrderValue         Money        `json:"min_order_value" gorm:"embedded;embeddedPrefix:min_order_"`
+	MaxRedemptions        *int         `json:"max_redemptions"`          // nil is unlimited
+	MaxRedemptionsPerUser *int         `json:"max_redemptions_per_user"` // nil is unlimited
+	StartsAt              *time.Time   `json:"starts_at"`
+	EndsAt                *time.Time   `json:"ends_at"`
+	FirstOrderOnly        bool         `json:"first_order_only"`
+	IsActive              bool         `json:"is_active" gorm:"default:true"`
+}
+
+// NormalizePromoCode makes promo codes case-insensitive
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// PromotionRedemption records the use of a promotion by an order
+type PromotionRedemption struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	PromotionID uint      `json:"promotion_id" gorm:"not null;index"`
+	UserID      uint      `json:"user_id" gorm:"not null"`
+	OrderID     uint      `json:"order_id" gorm:"not null;uniqueIndex"`
+	Discount    Money     `json:"discount" gorm:"embedded;embeddedPrefix:discount_"`
+}
+
+// OrderDiscount is a discount line of an order. The promotion's terms are copied
+// so the discount can be worked out again when the order's items change.
+type OrderDiscount struct {
+	ID           uint         `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	OrderID      uint         `json:"order_id" gorm:"not null;index"`
+	PromotionID  *uint        `json:"promotion_id"`
+	Code         string       `json:"code"`
+	Description  string       `json:"description"`
+	DiscountType DiscountType `json:"discount_type" gorm:"type:varchar(20);not null"`
+	PercentOff   int          `json:"percent_off"`
+	AmountOff    Money        `json:"amount_off" gorm:"embedded;embeddedPrefix:amount_off_"`
+	Amount       Money        `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusRequested RefundStatus = "REQUESTED"
+	RefundStatusApproved  RefundStatus = "APPROVED"
+	RefundStatusRejected  RefundStatus = "REJECTED"
+)
+
+// RefundReason is the reason code given when refunding or cancelling items
+type RefundReason string
+
+const (
+	RefundReasonMissingItem     RefundReason = "MISSING_ITEM"
+	RefundReasonWrongItem       RefundReason = "WRONG_ITEM"
+	RefundReasonQualityIssue    RefundReason = "QUALITY_ISSUE"
+	RefundReasonLateDelivery    RefundReason = "LATE_DELIVERY"
+	RefundReasonOutOfStock      RefundReason = "OUT_OF_STOCK"
+	RefundReasonCustomerRequest RefundReason = "CUSTOMER_REQUEST"
+	RefundReasonOther           RefundReason = "OTHER"
+)
+
+// IsValid reports whether the reason is one of the known codes
+func (r RefundReason) IsValid() bool {
+	switch r {
+	case RefundReasonMissingItem, RefundReasonWrongItem, RefundReasonQualityIssue, RefundReasonLateDelivery,
+		RefundReasonOutOfStock, RefundReasonCustomerRequest, RefundReasonOther:
+		return true
+	}
+	return false
+}
+
+// Refund returns some or all items of an order. Once approved the refunded
+// quantities are taken off the order total, and money already captured is paid
+// back to the customer. Amount is the price of the items when requested and
+// becomes the drop of the order total, after discounts, on approval.
+type Refund struct {
+	ID          uint         `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	OrderID     uint         `json:"order_id" gorm:"not null;index"`
+	RequestedBy uint         `json:"requested_by"`
+	ReasonCode  RefundReason `json:"reason_code" gorm:"type:varchar(30);not null"`
+	Note        string       `json:"note"`
+	Status      RefundStatus `json:"status" gorm:"type:varchar(20);not null"`
+	Amount      Money        `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	ReviewedBy  *uint        `json:"reviewed_by"`
+	ReviewedAt  *time.Time   `json:"reviewed_at"`
+	ReviewNote  string       `json:"review_note"`
+	Items       []RefundItem `json:"items" gorm:"foreignKey:RefundID"`
+}
+
+// RefundItem is a quantity of an order item included in a refund
+type RefundItem struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	RefundID    uint      `json:"refund_id" gorm:"not null;index"`
+	OrderItemID uint      `json:"order_item_id" gorm:"not null"`
+	Quantity    int       `json:"quantity"`
+	Amount      Money     `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+}
+
+// RefundRequest asks to refund items of an order. Without items every item
+// that has not been refunded yet is included.
+type RefundRequest struct {
+	ReasonCode RefundReason        `json:"reason_code" binding:"required"`
+	Note       string              `json:"note"`
+	Items      []RefundItemRequest `json:"items" binding:"dive"`
+}
+
+type RefundItemRequest struct {
+	OrderItemID uint `json:"order_item_id" binding:"required"`
+	Quantity    int  `json:"quantity" binding:"required,min=1"`
+}
+
+// RefundReview approves or rejects a requested refund
+type RefundReview struct {
+	Note string `json:"note"`
+}
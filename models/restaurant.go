@@ -5,23 +5,39 @@ import (
 )
 
 type Restaurant struct {
-	ID            uint      `gorm:"primarykey" json:"id"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	Email         string    `json:"email" gorm:"unique"`
-	Phone         string    `json:"phone" gorm:"unique"`
-	Address       string    `json:"address"`
-	City          string    `json:"city"`
-	State         string    `json:"state"`
-	Country       string    `json:"country"`
-	PostalCode    string    `json:"postal_code"`
-	Cuisine       string    `json:"cuisine"`
-	Rating        float32   `json:"rating" gorm:"type:decimal(3,2);default:0.0"`
-	IsActive      bool      `json:"is_active" gorm:"default:true"`
-	LogoURL       string    `json:"logo_url"`
-	CoverImageURL string    `json:"cover_image_url"`
-	Dishes        []Dish    `json:"dishes,omitempty" gorm:"foreignKey:RestaurantID"`
-	Orders        []Order   `json:"orders,omitempty" gorm:"foreignKey:RestaurantID"`
+	ID               uint               `gorm:"primarykey" json:"id"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+	Name             string             `json:"name"`
+	Description      string             `json:"description"`
+	Email            string             `json:"email" gorm:"unique"`
+	Phone            string             `json:"phone" gorm:"unique"`
+	Address          string             `json:"address"`
+	City             string             `json:"city"`
+	State            string             `json:"state"`
+	Country          string             `json:"country"`
+	PostalCode       string             `json:"postal_code"`
+	Latitude         *float64           `json:"latitude" gorm:"type:double precision"`
+	Longitude        *float64           `json:"longitude" gorm:"type:double precision"`
+	DeliveryRadiusKm float64            `json:"delivery_radius_km" gorm:"type:double precision;not null;default:5"`
+	Cuisine          string             `json:"cuisine"`
+	Currency         string             `json:"currency" gorm:"type:char(3);not null;default:'USD'"`
+	TimeZone         string             `json:"time_zone" gorm:"type:varchar(64);not null;default:'UTC'"`
+	SlotCapacity     int                `json:"slot_capacity" gorm:"not null;default:0"` // scheduled orders per slot, 0 is unlimited
+	DeliveryFee      Money              `json:"delivery_fee" gorm:"embedded;embeddedPrefix:delivery_fee_"`
+	DeliveryFeePerKm Money              `json:"delivery_fee_per_km" gorm:"embedded;embeddedPrefix:delivery_fee_per_km_"`
+	PackagingFee     Money              `json:"packaging_fee" gorm:"embedded;embeddedPrefix:packaging_fee_"`
+	Rating           float32            `json:"rating" gorm:"type:decimal(3,2);default:0.0"` // average of the reviews that are not hidden
+	ReviewCount      int                `json:"review_count" gorm:"not null;default:0"`
+	IsActive         bool               `json:"is_active" gorm:"default:true"`
+	LogoURL          string             `json:"logo_url"`
+	CoverImageURL    string             `json:"cover_image_url"`
+	Dishes           []Dish             `json:"dishes,omitempty" gorm:"foreignKey:RestaurantID"`
+	Orders           []Order            `json:"orders,omitempty" gorm:"foreignKey:RestaurantID"`
+	Members          []RestaurantMember `json:"members,omitempty" gorm:"foreignKey:RestaurantID"`
+	OpeningHours     []OpeningHours     `json:"opening_hours,omitempty" gorm:"foreignKey:RestaurantID"`
+	// IsOpenNow is computed from the opening hours and closures when the restaurant is returned
+	IsOpenNow bool `json:"is_open_now" gorm:"-"`
+	// DistanceKm is only populated by distance-based queries
+	DistanceKm *float64 `json:"distance_km,omitempty" gorm:"->;-:migration"`
 }
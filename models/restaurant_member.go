@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// MemberRole represents the role a user holds within a restaurant
+type MemberRole string
+
+const (
+	MemberRoleOwner        MemberRole = "OWNER"
+	MemberRoleManager      MemberRole = "MANAGER"
+	MemberRoleKitchenStaff MemberRole = "KITCHEN_STAFF"
+)
+
+// IsValid reports whether the role is one of the known member roles
+func (r MemberRole) IsValid() bool {
+	switch r {
+	case MemberRoleOwner, MemberRoleManager, MemberRoleKitchenStaff:
+		return true
+	}
+	return false
+}
+
+// RestaurantMember links a user to a restaurant they help manage
+type RestaurantMember struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	RestaurantID uint       `json:"restaurant_id" gorm:"not null;uniqueIndex:idx_restaurant_members_restaurant_user"`
+	Restaurant   Restaurant `json:"-" gorm:"foreignKey:RestaurantID"`
+	UserID       uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_restaurant_members_restaurant_user"`
+	User         User       `json:"user" gorm:"foreignKey:UserID"`
+	Role         MemberRole `json:"role" gorm:"type:varchar(20);not null"`
+	InvitedByID  *uint      `json:"invited_by_id,omitempty"`
+}
+
+// RestaurantMemberInvite represents the request to add a user to a restaurant
+type RestaurantMemberInvite struct {
+	Email string     `json:"email" binding:"required,email"`
+	Role  MemberRole `json:"role" binding:"required"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+)
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPublished ReviewStatus = "PUBLISHED"
+	ReviewStatusFlagged   ReviewStatus = "FLAGGED"
+	ReviewStatusHidden    ReviewStatus = "HIDDEN"
+)
+
+// IsValid reports whether the status is one of the known review statuses
+func (s ReviewStatus) IsValid() bool {
+	switch s {
+	case ReviewStatusPublished, ReviewStatusFlagged, ReviewStatusHidden:
+		return true
+	}
+	return false
+}
+
+// Review is a customer's rating of a delivered order, with optional ratings of
+// the dishes they had. A flagged review stays visible until an admin hides it;
+// hidden reviews are not listed and do not count towards ratings. Who flagged or
+// moderated a review is only shown to admins.
+type Review struct {
+	ID             uint         `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	OrderID        uint         `json:"order_id" gorm:"not null;uniqueIndex"`
+	UserID         uint         `json:"user_id" gorm:"not null"`
+	ReviewerName   string       `json:"reviewer_name" gorm:"->;-:migration"` // the author's display name
+	RestaurantID   uint         `json:"restaurant_id" gorm:"not null;index"`
+	Rating         int          `json:"rating" gorm:"not null"` // 1 to 5 stars
+	Comment        string       `json:"comment"`
+	Status         ReviewStatus `json:"status" gorm:"type:varchar(20);not null"`
+	FlaggedBy      *uint        `json:"flagged_by,omitempty"`
+	FlagReason     string       `json:"flag_reason,omitempty"`
+	ModeratedBy    *uint        `json:"moderated_by,omitempty"`
+	ModeratedAt    *time.Time   `json:"moderated_at,omitempty"`
+	ModerationNote string       `json:"moderation_note,omitempty"`
+	Reply          string       `json:"reply"` // the restaurant's public answer
+	RepliedBy      *uint        `json:"replied_by,omitempty"`
+	RepliedAt      *time.Time   `json:"replied_at"`
+	DishRatings    []DishRating `json:"dish_ratings" gorm:"foreignKey:ReviewID"`
+}
+
+// DishRating is the rating of one dish of a reviewed order
+type DishRating struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ReviewID  uint      `json:"review_id" gorm:"not null;index"`
+	DishID    uint      `json:"dish_id" gorm:"not null;index"`
+	Rating    int       `json:"rating" gorm:"not null"`
+}
+
+// ReviewRequest reviews a delivered order
+type ReviewRequest struct {
+	Rating      int                 `json:"rating" binding:"required,min=1,max=5"`
+	Comment     string              `json:"comment" binding:"max=2000"`
+	DishRatings []DishRatingRequest `json:"dish_ratings" binding:"dive"`
+}
+
+type DishRatingRequest struct {
+	DishID uint `json:"dish_id" binding:"required"`
+	Rating int  `json:"rating" binding:"required,min=1,max=5"`
+}
+
+// ReviewReply sets the restaurant's answer to a review; an empty reply removes it
+type ReviewReply struct {
+	Reply string `json:"reply" binding:"max=2000"`
+}
+
+// ReviewFlag reports a review to the moderators
+type ReviewFlag struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ReviewModeration publishes or hides a review
+type ReviewModeration struct {
+	Status ReviewStatus `json:"status" binding:"required"`
+	Note   string       `json:"note"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// SearchResults holds the restaurants and dishes matching a search query
+type SearchResults struct {
+	Query       string                   `json:"query"`
+	Restaurants []RestaurantSearchResult `json:"restaurants"`
+	Dishes      []DishSearchResult       `json:"dishes"`
+}
+
+// RestaurantSearchResult is a ranked restaurant match. Highlighted fields wrap
+// matched terms in <mark> tags.
+type RestaurantSearchResult struct {
+	ID            uint    `json:"id"`
+	Name          string  `json:"name"`
+	Cuisine       string  `json:"cuisine"`
+	City          string  `json:"city"`
+	LogoURL       string  `json:"logo_url"`
+	Rank          float64 `json:"rank"`
+	NameHighlight string  `json:"name_highlight"`
+	Snippet       string  `json:"snippet"`
+}
+
+// DishSearchResult is a ranked dish match. Highlighted fields wrap matched
+// terms in <mark> tags.
+type DishSearchResult struct {
+	ID             uint    `json:"id"`
+	Name           string  `json:"name"`
+	Category       string  `json:"category"`
+	Price          Money   `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+	ImageURL       string  `json:"image_url"`
+	RestaurantID   uint    `json:"restaurant_id"`
+	RestaurantName string  `json:"restaurant_name"`
+	Rank           float64 `json:"rank"`
+	NameHighlight  string  `json:"name_highlight"`
+	Snippet        string  `json:"snippet"`
+}
@@ -4,6 +4,25 @@ import (
 	"time"
 )
 
+// UserRole represents the access level of a user
+type UserRole string
+
+const (
+	UserRoleCustomer UserRole = "CUSTOMER"
+	UserRoleOwner    UserRole = "OWNER"
+	UserRoleAdmin    UserRole = "ADMIN"
+	UserRoleDriver   UserRole = "DRIVER"
+)
+
+// IsValid reports whether the role is one of the known user roles
+func (r UserRole) IsValid() bool {
+	switch r {
+	case UserRoleCustomer, UserRoleOwner, UserRoleAdmin, UserRoleDriver:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system
 type User struct {
 	ID         uint      `gorm:"primarykey" json:"id"`
@@ -11,6 +30,7 @@ type User struct {
 	UpdatedAt  time.Time `json:"updated_at"`
 	Email      string    `json:"email" gorm:"unique;not null"`
 	Password   string    `json:"-" gorm:"not null"`
+	Role       UserRole  `json:"role" gorm:"type:varchar(20);not null;default:'CUSTOMER'"`
 	Name       string    `json:"name"`
 	Phone      string    `json:"phone"`
 	Address    string    `json:"address"`
@@ -18,6 +38,8 @@ type User struct {
 	State      string    `json:"state"`
 	Country    string    `json:"country"`
 	PostalCode string    `json:"postal_code"`
+	Latitude   *float64  `json:"latitude" gorm:"type:double precision"`
+	Longitude  *float64  `json:"longitude" gorm:"type:double precision"`
 	Orders     []Order   `json:"orders,omitempty" gorm:"foreignKey:UserID"`
 }
 
@@ -29,13 +51,20 @@ type UserLogin struct {
 
 // UserRegister represents the registration request
 type UserRegister struct {
-	Name       string `json:"name" binding:"required"`
-	Email      string `json:"email" binding:"required,email"`
-	Password   string `json:"password" binding:"required,min=6"`
-	Phone      string `json:"phone"`
-	Address    string `json:"address"`
-	City       string `json:"city"`
-	State      string `json:"state"`
-	Country    string `json:"country"`
-	PostalCode string `json:"postal_code"`
+	Name       string   `json:"name" binding:"required"`
+	Email      string   `json:"email" binding:"required,email"`
+	Password   string   `json:"password" binding:"required,min=6"`
+	Phone      string   `json:"phone"`
+	Address    string   `json:"address"`
+	City       string   `json:"city"`
+	State      string   `json:"state"`
+	Country    string   `json:"country"`
+	PostalCode string   `json:"postal_code"`
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+}
+
+// UserRoleUpdate represents the request to change a user's role
+type UserRoleUpdate struct {
+	Role UserRole `json:"role" binding:"required"`
 }
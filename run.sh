@@ -38,14 +38,14 @@ fi
 echo -e "${YELLOW}Installing dependencies...${NC}"
 go mod download
 
-# Run database migrations
-echo -e "${YELLOW}Running database migrations...${NC}"
-psql -U postgres -f database/sql/schema.sql
-
 # Build the project
 echo -e "${YELLOW}Building the project...${NC}"
 go build -o tumdum-backend
 
+# Run database migrations
+echo -e "${YELLOW}Running database migrations...${NC}"
+./tumdum-backend migrate up
+
 # Run the application
 echo -e "${GREEN}Starting the application...${NC}"
 ./tumdum-backend 
\ No newline at end of file